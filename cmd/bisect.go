@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newBisectCmd builds the parent command of the bisect mode, which narrows the enabled
+// mods down to the one causing a problem like git bisect does for commits.
+func newBisectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "Find the mod causing a problem by enabling half of the suspects at each step",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "start",
+			Short: "Suspect every enabled mod and enable the first half of them",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				playset := loadPlayset()
+				if b, err := mods.LoadBisect(playset.SettingsPath); err != nil || b != nil {
+					cmd.SilenceUsage = true
+					if err != nil {
+						return err
+					}
+					return errors.New("a bisect is already running, finish it or run bisect reset first")
+				}
+				described, err := playset.DescribedMods(cmd.Context())
+				if err != nil {
+					return err
+				}
+				modList := playset.EnabledOrder(described)
+				b := mods.StartBisect(modList, playset.Registry, playset.EnabledIds())
+				return bisectStep(cmd, playset, b, modList)
+			},
+		},
+		newBisectMarkCmd("good", "The problem did not show, so the enabled suspects are cleared", false),
+		newBisectMarkCmd("bad", "The problem showed, so one of the enabled suspects causes it", true),
+		&cobra.Command{
+			Use:   "reset",
+			Short: "Stop the bisect and restore the original enabled mods",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				playset := loadPlayset()
+				b, err := mods.LoadBisect(playset.SettingsPath)
+				if err != nil {
+					return err
+				}
+				if b == nil {
+					prettylog.PrintPretty("bisect", "No bisect is running", prettylog.LogInfo)
+					return nil
+				}
+				return bisectRestore(playset, b)
+			},
+		},
+	)
+	return cmd
+}
+
+// newBisectMarkCmd builds the good and bad subcommands.
+func newBisectMarkCmd(use, short string, bad bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			b, err := mods.LoadBisect(playset.SettingsPath)
+			if err != nil {
+				return err
+			}
+			if b == nil {
+				cmd.SilenceUsage = true
+				return errors.New("no bisect is running, start one with bisect start")
+			}
+			// Dependencies are resolved against every mod of the original playset, not only
+			// the ones the current step left enabled
+			described, err := playset.DescribedMods(cmd.Context())
+			if err != nil {
+				return err
+			}
+			modList := mods.LoadOrder(described, b.Original)
+			b.Mark(bad, modList, playset.Registry)
+			return bisectStep(cmd, playset, b, modList)
+		},
+	}
+}
+
+// bisectStep writes the enabled mods of the current step, or reports the suspects left and
+// restores the original playset when the bisect is done.
+func bisectStep(cmd *cobra.Command, playset *mods.Playset, b *mods.Bisect, modList []*mods.Mod) error {
+	if b.Done() {
+		names := bisectNames(b.Suspects, modList)
+		switch len(names) {
+		case 0:
+			prettylog.PrintPretty("bisect", "No suspect left, the problem does not depend on the enabled mods", prettylog.LogWarning)
+		case 1:
+			prettylog.PrintPretty("bisect", "The problem is caused by "+names[0], prettylog.LogInfo)
+		default:
+			prettylog.PrintPretty("bisect", "These mods depend on each other and could not be tested apart: "+strings.Join(names, ", "), prettylog.LogInfo)
+		}
+		return bisectRestore(playset, b)
+	}
+	if err := mods.SaveBisect(playset.SettingsPath, b); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	playset.Command = fmt.Sprintf("bisect step %d", b.Steps)
+	playset.WriteEnabled(b.Enabled(), mods.BakExt)
+	prettylog.PrintPretty("bisect", fmt.Sprintf("Step %d: enabled %d of %d mods, %d suspects left (about %d more steps)",
+		b.Steps, len(b.Testing), len(b.Original), len(b.Suspects), bits.Len(uint(len(b.Suspects)-1))), prettylog.LogInfo)
+	prettylog.PrintPretty("bisect", "Start the game, then run bisect good or bisect bad", prettylog.LogInfo)
+	return nil
+}
+
+// bisectRestore writes back the original enabled mods and removes the bisect state.
+func bisectRestore(playset *mods.Playset, b *mods.Bisect) error {
+	playset.Command = "bisect reset"
+	playset.WriteEnabled(b.Original, mods.BakExt)
+	if err := mods.ClearBisect(playset.SettingsPath); err != nil {
+		return err
+	}
+	prettylog.PrintPretty("bisect", fmt.Sprintf("Restored the original %d enabled mods", len(b.Original)), prettylog.LogInfo)
+	return nil
+}
+
+// bisectNames returns the names of the mods with the given IDs, falling back to the ID.
+func bisectNames(ids []string, modList []*mods.Mod) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name := id
+		for _, mod := range modList {
+			if mod.ModId == id {
+				name = mod.Name
+				break
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// noCache disables the descriptor and mod file cache, set by the persistent --no-cache flag.
+var noCache bool
+
+// openCache sets the cache used by every command, unless --no-cache is given or there is no
+// user cache directory.
+func openCache() {
+	if noCache {
+		return
+	}
+	dir, err := mods.DefaultCacheDir()
+	if err != nil {
+		prettylog.PrintPretty("main", "Running without cache: "+err.Error(), prettylog.LogWarning)
+		return
+	}
+	mods.DefaultCache = mods.OpenCache(dir)
+}
+
+// pruneCache drops the entries of uninstalled mods when the last prune is due.
+func pruneCache() {
+	if mods.DefaultCache == nil {
+		return
+	}
+	if _, err := mods.DefaultCache.PruneIfDue(pruneInterval); err != nil {
+		prettylog.PrintPretty("main", "Could not prune the cache: "+err.Error(), prettylog.LogWarning)
+	}
+}
+
+// readsCache makes cmd and its subcommands prune the cache before they run, so only the
+// commands reading descriptors or mod folders pay for it and cache management sees the
+// entries as they are.
+func readsCache(cmd *cobra.Command) *cobra.Command {
+	cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		pruneCache()
+	}
+	for _, sub := range cmd.Commands() {
+		readsCache(sub)
+	}
+	return cmd
+}
+
+// pruneInterval is how often the cache drops the entries of uninstalled mods on its own.
+const pruneInterval = 24 * time.Hour
+
+// newCacheCmd builds the parent command managing the descriptor and mod file cache.
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect, prune or clear the cache of parsed descriptors, mod file lists, hashes and DLC checks",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "stats",
+			Short: "Show the location, entries and size of the cache",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cache, err := cacheOrError()
+				if err != nil {
+					return err
+				}
+				stats, err := cache.Stats()
+				if err != nil {
+					return err
+				}
+				out := cmd.OutOrStdout()
+				fmt.Fprintln(out, cache.Dir)
+				for _, s := range stats {
+					fmt.Fprintf(out, "%-12s %6d entries %10s\n", s.Kind, s.Entries, formatSize(s.Bytes))
+				}
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "prune",
+			Short: "Remove the entries of descriptors and mod folders that no longer exist, such as uninstalled mods",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cache, err := cacheOrError()
+				if err != nil {
+					return err
+				}
+				removed, err := cache.Prune()
+				if err != nil {
+					return err
+				}
+				prettylog.PrintPretty("cache", fmt.Sprintf("Removed %d entries from %s", removed, cache.Dir), prettylog.LogInfo)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "clear",
+			Short: "Remove every cache entry, so the next run reads all descriptors and files again",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cache, err := cacheOrError()
+				if err != nil {
+					return err
+				}
+				if err := cache.Clear(); err != nil {
+					return err
+				}
+				prettylog.PrintPretty("cache", "Cleared "+cache.Dir, prettylog.LogInfo)
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+// cacheOrError returns the cache in the default location, even with --no-cache.
+func cacheOrError() (*mods.Cache, error) {
+	dir, err := mods.DefaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return mods.OpenCache(dir), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newChangesCmd builds the command reporting mod changes since the last sort.
+func newChangesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "changes",
+		Short: "Report mods whose content changed since the last sort",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			old, err := mods.LoadState(playset.SettingsPath)
+			if err != nil {
+				return err
+			}
+			if old == nil {
+				prettylog.PrintPretty("changes", "No sort recorded yet, run the sorter first", prettylog.LogWarning)
+				return nil
+			}
+			cur, err := mods.TakeSnapshot(mods.GetModList(playset.Registry), playset.EnabledIds(), playset.Registry)
+			if err != nil {
+				return err
+			}
+			changes := mods.DiffStates(old, cur)
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%d mods changed since the sort of %s\n", len(changes), old.SortedAt.Format("2006-01-02 15:04"))
+			resort := false
+			for _, c := range changes {
+				printChange(out, c)
+				resort = resort || c.NeedsResort()
+			}
+			if resort {
+				prettylog.PrintPretty("changes", "Dependencies, tags or overrides changed, a re-sort is recommended", prettylog.LogWarning)
+			}
+			return nil
+		},
+	}
+}
+
+func printChange(out io.Writer, c mods.ModChange) {
+	version := ""
+	if c.VersionFrom != c.VersionTo {
+		version = fmt.Sprintf(" (%s -> %s)", c.VersionFrom, c.VersionTo)
+	}
+	fmt.Fprintf(out, "%s: %s%s\n", c.Status, c.Name, version)
+	lists := []struct {
+		label string
+		items []string
+	}{
+		{"+ dependencies", c.AddedDependencies},
+		{"- dependencies", c.RemovedDependencies},
+		{"+ tags", c.AddedTags},
+		{"- tags", c.RemovedTags},
+		{"overrides", c.OverriddenFiles},
+	}
+	for _, l := range lists {
+		if len(l.items) > 0 {
+			fmt.Fprintf(out, "    %s: %s\n", l.label, strings.Join(l.items, ", "))
+		}
+	}
+	if n := len(c.AddedFiles) + len(c.RemovedFiles) + len(c.ModifiedFiles); n > 0 {
+		fmt.Fprintf(out, "    files: %d added, %d removed, %d modified\n", len(c.AddedFiles), len(c.RemovedFiles), len(c.ModifiedFiles))
+	}
+}
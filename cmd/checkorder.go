@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newCheckOrderCmd builds the command auditing the current load order without changing it.
+func newCheckOrderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-order",
+		Short: "Validate the current enabled_mods order against the sorting rules without changing it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			modList, err := playset.DescribedMods(cmd.Context())
+			if err != nil {
+				return err
+			}
+			order := playset.EnabledOrder(modList)
+			if len(order) == 0 {
+				prettylog.PrintPretty("check-order", "No enabled_mods found in dlc_load.json", prettylog.LogWarning)
+				return nil
+			}
+			violations := mods.CheckOrder(order, playset.Registry, playset.EnabledIds(), playset.Rules)
+			violations = append(violations, mods.CheckDisabledDependencies(order, playset.Registry, playset.EnabledIds())...)
+			// Pins refer to positions in the full modsOrder of game_data.json
+			if len(playset.DisplayOrder()) > 0 {
+				pins, err := mods.LoadPins(playset.SettingsPath)
+				if err != nil {
+					return err
+				}
+				violations = append(violations, mods.CheckPins(playset.CurrentOrder(modList), pins)...)
+			}
+			out := cmd.OutOrStdout()
+			for _, v := range violations {
+				fmt.Fprintf(out, "%-20s %s: %s\n", v.Kind, v.Mod.Name, v.Cause)
+			}
+			if len(violations) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d violations in the load order of %d enabled mods", len(violations), len(order))
+			}
+			prettylog.PrintPretty("check-order", fmt.Sprintf("Load order of %d enabled mods satisfies all rules", len(order)), prettylog.LogInfo)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newChecksumCmd builds the command reporting which enabled mods change the game checksum.
+func newChecksumCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "checksum",
+		Short: "Report which enabled mods change the game checksum (achievements, Ironman)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			loadOrder := mods.LoadOrder(mods.GetModList(playset.Registry), playset.EnabledIds())
+			report, err := mods.BuildChecksumReport(loadOrder, playset.Registry)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			affecting := 0
+			for _, mc := range report.Mods {
+				detail := ""
+				if mc.Impact == mods.ImpactChecksum {
+					affecting++
+					detail = fmt.Sprintf(" (%d checksummed files)", len(mc.ChecksumFiles))
+				}
+				if len(mc.UnknownFolders) > 0 {
+					detail += " unknown folders: " + strings.Join(mc.UnknownFolders, ", ")
+				}
+				fmt.Fprintf(out, "%-9s %s%s\n", mc.Impact, mc.Mod.Name, detail)
+			}
+			fmt.Fprintf(out, "combined checksum: %s\n", report.Combined)
+			if affecting > 0 {
+				prettylog.PrintPretty("checksum", fmt.Sprintf("%d of %d enabled mods change the checksum, achievements and Ironman are disabled", affecting, len(report.Mods)), prettylog.LogWarning)
+			}
+			return nil
+		},
+	}
+}
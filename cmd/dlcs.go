@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newDlcsCmd builds the command listing the DLCs the enabled mods test for.
+func newDlcsCmd() *cobra.Command {
+	var gameDir string
+	var strict bool
+	cmd := &cobra.Command{
+		Use:   "dlcs",
+		Short: "List the DLCs the scripts of the enabled mods check for and warn about mods requiring a disabled or missing DLC",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			scanned, err := mods.ScanDlcs(playset.EnabledOrder(mods.GetModList(playset.Registry)), playset.Registry)
+			if err != nil {
+				prettylog.PrintPretty("dlcs", "Some mods could not be scanned:\n"+err.Error(), prettylog.LogWarning)
+			}
+			out := cmd.OutOrStdout()
+			for _, m := range scanned {
+				fmt.Fprintln(out, m.Mod.Name)
+				for _, use := range m.Uses {
+					fmt.Fprintf(out, "  %-4s %s (%d checks, %s:%d)\n", dlcStrength(use), use.Dlc, use.Checks, use.File, use.Line)
+				}
+			}
+
+			install := readGameInstall("dlcs", gameDir)
+			if len(install.Dlcs) == 0 {
+				prettylog.PrintPretty("dlcs", "No DLCs found in the game folder, pass --game-dir to check the DLCs against the current setup", prettylog.LogWarning)
+				return nil
+			}
+			hard := 0
+			for _, issue := range mods.CheckDlcUses(scanned, install, playset.DisabledDlcs()) {
+				state := "is disabled"
+				if issue.Kind == mods.DlcIssueMissing {
+					state = "is not installed"
+				}
+				if issue.Use.Hard {
+					hard++
+					prettylog.PrintPretty("dlcs", fmt.Sprintf("%s requires %s, which %s (%s:%d)", issue.Mod.Name, issue.Use.Dlc, state, issue.Use.File, issue.Use.Line), prettylog.LogWarning)
+					continue
+				}
+				prettylog.PrintPretty("dlcs", fmt.Sprintf("%s checks for %s, which %s; the parts depending on it are skipped", issue.Mod.Name, issue.Use.Dlc, state), prettylog.LogInfo)
+			}
+			if strict && hard > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d DLC requirements of enabled mods are disabled or missing", hard)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&gameDir, "game-dir", "", "game installation folder (defaults to the Steam install)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "exit non-zero when a mod requires a disabled or missing DLC")
+	return cmd
+}
+
+func dlcStrength(use mods.DlcUse) string {
+	if use.Hard {
+		return "hard"
+	}
+	return "soft"
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newDuplicatesCmd builds the command listing duplicate installs and choosing the copy to keep enabled.
+func newDuplicatesCmd() *cobra.Command {
+	var keep string
+	cmd := &cobra.Command{
+		Use:   "duplicates",
+		Short: "List mods installed more than once and choose which copy stays enabled",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			idList := playset.EnabledIds()
+			if keep != "" {
+				if _, ok := playset.Registry[keep]; !ok {
+					return fmt.Errorf("unknown mod %s", keep)
+				}
+				newIds, ok := mods.KeepDuplicate(playset.Registry, idList, keep)
+				if !ok {
+					return fmt.Errorf("mod %s has no duplicates", keep)
+				}
+				modList := playset.CurrentOrder(mods.GetModList(playset.Registry))
+				playset.Command = "duplicates --keep " + keep
+				playset.Write(modList, newIds, mods.BakExt)
+				prettylog.PrintPretty("duplicates", "Kept "+keep+" enabled, disabled its other copies", prettylog.LogInfo)
+				return nil
+			}
+			groups := mods.FindDuplicates(playset.Registry)
+			out := cmd.OutOrStdout()
+			for _, group := range groups {
+				fmt.Fprintln(out, "duplicate installs:")
+				for _, h := range group {
+					d := playset.Registry[h]
+					id, _ := d["gameRegistryId"].(string)
+					state := "disabled"
+					if mods.Contains(idList, id) {
+						state = "enabled"
+					}
+					fmt.Fprintf(out, "  %s  %-8s %-8v %s (%v)\n", h, state, d["source"], d["displayName"], d["dirPath"])
+				}
+			}
+			if len(groups) > 0 {
+				prettylog.PrintPretty("duplicates", "Use --keep <hash> to choose the copy that stays enabled", prettylog.LogInfo)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keep, "keep", "", "registry hash of the copy to keep enabled")
+	return cmd
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newHistoryCmd builds the command listing the journaled file changes.
+func newHistoryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history",
+		Short: "List every change the tool made to the launcher files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			entries, err := mods.LoadHistory(playset.SettingsPath)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				prettylog.PrintPretty("history", "No changes recorded yet", prettylog.LogInfo)
+				return nil
+			}
+			out := cmd.OutOrStdout()
+			for _, entry := range entries {
+				var files []string
+				for _, f := range entry.Files {
+					files = append(files, filepath.Base(f.Path))
+				}
+				fmt.Fprintf(out, "%4d  %s  %-28s %s\n", entry.Id, entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Command, strings.Join(files, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// newUndoCmd builds the command restoring the files to their state before a journal entry.
+func newUndoCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "undo [n]",
+		Short: "Restore the files changed by history entry n (default: the last) and every later entry",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := 0
+			if len(args) == 1 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid history entry %q", args[0])
+				}
+				id = n
+			}
+			playset := loadPlayset()
+			entry, err := mods.Undo(playset.SettingsPath, id, force)
+			if err != nil {
+				cmd.SilenceUsage = true
+				if errors.Is(err, mods.ErrChangedExternally) {
+					return fmt.Errorf("%w (use --force to restore anyway)", err)
+				}
+				return err
+			}
+			if entry == nil {
+				prettylog.PrintPretty("undo", "Files already match the restored state, nothing changed", prettylog.LogInfo)
+				return nil
+			}
+			for _, f := range entry.Files {
+				prettylog.PrintPretty("undo", "Restored "+f.Path, prettylog.LogInfo)
+			}
+			prettylog.PrintPretty("undo", fmt.Sprintf("Recorded as history entry %d", entry.Id), prettylog.LogInfo)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "restore even when the files were changed outside the tool")
+	return cmd
+}
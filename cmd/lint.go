@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+)
+
+// newLintCmd builds the command checking descriptors and folder layout of all registered mods.
+func newLintCmd() *cobra.Command {
+	var asJSON, strict bool
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check descriptors and folder layout of all registered mods",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			modList := mods.GetModList(playset.Registry)
+			// Lint reports every descriptor that cannot be read, so only a cancelled load is an error
+			mods.LoadModDescriptions(cmd.Context(), modList, playset.Registry, make(map[string][]string), playset.SettingsPath, mods.DefaultDescriptorWorkers)
+			if err := cmd.Context().Err(); err != nil {
+				return err
+			}
+			issues := mods.LintMods(modList, playset.Registry, playset.SettingsPath)
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if issues == nil {
+					issues = []mods.LintIssue{}
+				}
+				if err := enc.Encode(issues); err != nil {
+					return err
+				}
+			} else {
+				for _, i := range issues {
+					fmt.Fprintf(out, "%-7s %-18s %s: %s\n", i.Severity, i.Check, i.Mod, i.Message)
+				}
+				fmt.Fprintf(out, "%d errors, %d warnings, %d infos\n",
+					mods.CountSeverity(issues, mods.SeverityError),
+					mods.CountSeverity(issues, mods.SeverityWarning),
+					mods.CountSeverity(issues, mods.SeverityInfo))
+			}
+			if strict && mods.CountSeverity(issues, mods.SeverityError)+mods.CountSeverity(issues, mods.SeverityWarning) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("lint found errors or warnings")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print issues as JSON")
+	cmd.Flags().BoolVar(&strict, "strict", false, "exit non-zero when any error or warning is found")
+	return cmd
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newLockCmd builds the command writing a manifest of the enabled playset.
+func newLockCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Write the order, Steam ID, version and content hash of every enabled mod to a lock file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			lock, err := mods.BuildLock(playset.EnabledOrder(mods.GetModList(playset.Registry)), playset.Registry)
+			if err != nil {
+				return err
+			}
+			if err := mods.SaveLock(output, lock); err != nil {
+				return err
+			}
+			prettylog.PrintPretty("lock", fmt.Sprintf("Locked %d mods in %s", len(lock.Mods), output), prettylog.LogInfo)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", mods.DefaultLockFile, "lock file to write")
+	return cmd
+}
+
+// newVerifyLockCmd builds the command comparing the local playset against a lock file.
+func newVerifyLockCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-lock <file>",
+		Short: "Report mods missing, extra, reordered or with different content compared to a lock file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			want, err := mods.LoadLock(args[0])
+			if err != nil {
+				return err
+			}
+			playset := loadPlayset()
+			got, err := mods.BuildLock(playset.EnabledOrder(mods.GetModList(playset.Registry)), playset.Registry)
+			if err != nil {
+				return err
+			}
+			diffs := mods.CompareLocks(want, got)
+			out := cmd.OutOrStdout()
+			for _, d := range diffs {
+				switch d.Kind {
+				case mods.LockMissing:
+					fmt.Fprintf(out, "missing    #%-3d %s%s\n", d.Want.Position, d.Want.Name, steamSuffix(d.Want.SteamId))
+				case mods.LockExtra:
+					fmt.Fprintf(out, "extra      #%-3d %s%s\n", d.Got.Position, d.Got.Name, steamSuffix(d.Got.SteamId))
+				case mods.LockReordered:
+					fmt.Fprintf(out, "reordered  #%-3d %s (locally #%d)\n", d.Want.Position, d.Want.Name, d.Got.Position)
+				case mods.LockContent:
+					fmt.Fprintf(out, "content    #%-3d %s (version %s, locally %s)\n", d.Want.Position, d.Want.Name, orDash(d.Want.Version), orDash(d.Got.Version))
+				}
+			}
+			if len(diffs) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d differences from %s", len(diffs), args[0])
+			}
+			prettylog.PrintPretty("verify-lock", fmt.Sprintf("All %d mods match %s", len(want.Mods), args[0]), prettylog.LogInfo)
+			return nil
+		},
+	}
+}
+
+func steamSuffix(steamId string) string {
+	if steamId == "" {
+		return ""
+	}
+	return " (Steam " + steamId + ")"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
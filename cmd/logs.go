@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	"stellaris-mod-sorter-go/internal/paradox"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newLogsCmd builds the parent command of the game log tools.
+func newLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Inspect the game logs of the settings directory",
+	}
+	cmd.AddCommand(newLogsAnalyzeCmd())
+	return cmd
+}
+
+// newLogsAnalyzeCmd builds the command ranking the enabled mods by their errors in the game logs.
+func newLogsAnalyzeCmd() *cobra.Command {
+	var logPaths []string
+	var asJSON bool
+	var top int
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Attribute error.log and game.log entries to the enabled mods providing the referenced files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			if len(logPaths) == 0 {
+				logPaths = []string{
+					filepath.Join(playset.SettingsPath, mods.LogsDir, mods.ErrorLogFile),
+					filepath.Join(playset.SettingsPath, mods.LogsDir, mods.GameLogFile),
+				}
+			}
+			described, err := playset.DescribedMods(cmd.Context())
+			if err != nil {
+				return err
+			}
+			index := mods.NewFileIndex(mods.LoadOrder(described, playset.EnabledIds()), playset.Registry)
+
+			var errs []mods.LogError
+			for _, path := range logPaths {
+				file, err := os.Open(path)
+				if err != nil {
+					prettylog.PrintPretty("logs", "Skipping "+path+": "+err.Error(), prettylog.LogWarning)
+					continue
+				}
+				entries, err := paradox.ParseLog(file)
+				file.Close()
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				// error.log only holds errors, other logs mix them with progress messages
+				if filepath.Base(path) != mods.ErrorLogFile {
+					var filtered []paradox.LogEntry
+					for _, e := range entries {
+						if e.IsError() {
+							filtered = append(filtered, e)
+						}
+					}
+					entries = filtered
+				}
+				errs = append(errs, mods.AnalyzeLog(filepath.Base(path), entries, index)...)
+			}
+
+			report := mods.BuildLogReport(errs)
+			if top > 0 && len(report.Mods) > top {
+				report.Mods = report.Mods[:top]
+			}
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(report)
+			}
+			for _, m := range report.Mods {
+				var categories []string
+				for _, c := range m.SortedCategories() {
+					categories = append(categories, fmt.Sprintf("%s %d", c, m.Categories[c]))
+				}
+				fmt.Fprintf(out, "%6d  %-40s %s\n", m.Count, m.Name, strings.Join(categories, ", "))
+			}
+			fmt.Fprintf(out, "%d errors, %d in files no enabled mod provides, %d without a file\n",
+				report.Total, report.Unattributed, report.WithoutFile)
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&logPaths, "log", nil, "log file to analyze (repeatable, defaults to logs/error.log and logs/game.log)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the report as JSON")
+	cmd.Flags().IntVar(&top, "top", 0, "only list the N mods with the most errors")
+	return cmd
+}
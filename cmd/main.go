@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
@@ -10,77 +15,105 @@ import (
 	"stellaris-mod-sorter-go/internal/mods"
 	prettylog "stellaris-mod-sorter-go/internal/utils"
 )
+
+// gameKey selects the game profile, set by the persistent --game flag.
+var gameKey = config.DefaultGame
+
 func main() {
+	var verifyStable, minimal, enabledOnly, keepDisabled, skipValidation bool
 	var rootCmd = &cobra.Command{
 		Use:   "stellaris-mod-sorter",
 		Short: "Stellaris Mod Sorter and Manager",
 		Long:  `A CLI tool for sorting, validating, and managing Stellaris mods and registries.`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			openCache()
+		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			pruneCache()
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			// Default mode: run the original mod sorting logic
-			const modsRegistry = "mods_registry.json"
-			const bakExt = ".bak"
-
-			settingsPath, err := config.FindStellarisPath(modsRegistry)
-			if err != nil {
-				prettylog.PrintError("main", err, fmt.Sprintf("Unable to locate %s", modsRegistry), true)
-			}
-			prettylog.PrintPretty("main", fmt.Sprintf("Found Stellaris settings at %s", settingsPath), prettylog.LogInfo)
-
-			// Load enabled mods and display order
-			enabledModsRaw, dlcLoadPath := mods.LoadJsonOrder(settingsPath, "dlc_load.json", bakExt)
-			displayOrderRaw, gameDataPath := mods.LoadJsonOrder(settingsPath, "game_data.json", bakExt)
-
-			// Load mods registry
-			modsRegistryPath := settingsPath + string(os.PathSeparator) + modsRegistry
-			file, err := os.Open(modsRegistryPath)
-			if err != nil {
-				prettylog.PrintError("main", err, fmt.Sprintf("Could not open %s", modsRegistryPath), true)
+			playset := loadPlayset()
+			playset.Minimal = minimal
+			playset.EnabledOnly = enabledOnly || keepDisabled
+			playset.KeepDisabledPositions = keepDisabled
+			if err := mods.ValidateLauncherFiles(playset.SettingsPath); err != nil {
+				if !skipValidation {
+					prettylog.PrintError("main", err, "Refusing to sort invalid launcher files, pass --skip-validation to override", true)
+				}
+				prettylog.PrintError("main", err, "Sorting despite invalid launcher files", false)
 			}
-			defer file.Close()
 
-			var data map[string]map[string]interface{}
-			if err := mods.DecodeJSON(file, &data); err != nil {
-				prettylog.PrintError("main", err, fmt.Sprintf("Could not decode %s", modsRegistryPath), true)
+			idList := playset.EnabledIds()
+			if len(idList) == 0 {
+				prettylog.PrintPretty("main", "No enabled_mods found in dlc_load.json", prettylog.LogWarning)
+				os.Exit(1)
 			}
 
-			modList := mods.GetModList(data)
-			modList = mods.TweakModOrder(modList)
-
-			var idList []string
-			if raw, ok := enabledModsRaw["enabled_mods"]; ok {
-				if arr, ok := raw.([]interface{}); ok {
-					for _, v := range arr {
-						if s, ok := v.(string); ok {
-							idList = append(idList, s)
-						}
-					}
+			var solution mods.Solution
+			var err error
+			if verifyStable {
+				if solution, err = playset.VerifyStable(cmd.Context(), idList); err != nil {
+					prettylog.PrintError("main", err, "Refusing to write", true)
 				}
+			} else if solution, err = playset.Solve(cmd.Context(), idList); err != nil {
+				prettylog.PrintError("main", err, "Sort interrupted, nothing written", true)
 			}
-			if len(idList) == 0 {
-				prettylog.PrintPretty("main", "No enabled_mods found in dlc_load.json", prettylog.LogWarning)
-				os.Exit(1)
+			modList := solution.Order
+			if len(modList) == 0 {
+				prettylog.PrintPretty("main", "No mods found in mods_registry.json, nothing to sort", prettylog.LogWarning)
+				return
 			}
 
-			allTags := make(map[string][]string)
-			mods.GetModDescription(modList, data, allTags, settingsPath)
-			modList = mods.SortAfterTags(allTags, modList)
-			modList = mods.SpecialOrder(modList)
-			modList = mods.SortDependencies(modList, idList, data)
+			baseline := playset.BaselineOrder(modList)
 
 			// Update and write output files
-			displayOrderRaw["modsOrder"] = mods.GetModHashKeys(modList)
-			enabledModsRaw["enabled_mods"] = mods.GetModIdsReversed(modList, idList)
-			mods.WriteJsonOrder(enabledModsRaw, dlcLoadPath, bakExt)
-			mods.WriteJsonOrder(displayOrderRaw, gameDataPath, bakExt)
+			playset.Write(modList, idList, mods.BakExt)
+			if err := playset.RecordState(modList, idList); err != nil {
+				prettylog.PrintError("main", err, "Could not record mod state", false)
+			}
 
 			for i, mod := range modList {
 				prettylog.PrintPretty("main", fmt.Sprintf("%d: %s", i, mod.SortedKey), prettylog.LogMessage)
 			}
+			moved := mods.MovedCount(baseline, modList)
+			prettylog.PrintPretty("main", fmt.Sprintf("%d of %d mods moved", moved, len(modList)), prettylog.LogInfo)
+			for _, v := range solution.Broken {
+				prettylog.PrintPretty("main", fmt.Sprintf("Broken %s constraint: %s %s", v.Kind, v.Mod.Name, v.Cause), prettylog.LogWarning)
+			}
 			prettylog.PrintPretty("main", "done", prettylog.LogInfo)
 		},
 	}
 
+	rootCmd.PersistentFlags().StringVar(&gameKey, "game", config.DefaultGame, "game profile to use: "+strings.Join(config.GameKeys(), ", "))
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "read every descriptor and mod file instead of using the cache")
+	rootCmd.Flags().BoolVar(&verifyStable, "verify-stable", false, "sort twice and fail without writing if the results differ")
+	rootCmd.Flags().BoolVar(&enabledOnly, "enabled-only", false, "sort only the enabled mods and keep the disabled ones in their current relative order at the end")
+	rootCmd.Flags().BoolVar(&keepDisabled, "keep-disabled-positions", false, "like --enabled-only, but leave the disabled mods at their current positions")
+	rootCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "write even when the launcher files fail schema validation")
+	rootCmd.Flags().BoolVar(&minimal, "minimal", false, "start from the current order and only move mods breaking a dependency, pin or rule")
+
 	rootCmd.AddCommand(
+		readsCache(newTuiCmd()),
+		readsCache(newServeCmd()),
+		newWorkshopCmd(),
+		readsCache(newChangesCmd()),
+		newChecksumCmd(),
+		newDuplicatesCmd(),
+		readsCache(newCheckOrderCmd()),
+		readsCache(newLintCmd()),
+		newWhereCmd(),
+		newHistoryCmd(),
+		newUndoCmd(),
+		readsCache(newLogsCmd()),
+		readsCache(newBisectCmd()),
+		newSavesCmd(),
+		newShareCmd(),
+		newApplyShareCmd(),
+		readsCache(newLockCmd()),
+		readsCache(newVerifyLockCmd()),
+		newCacheCmd(),
+		readsCache(newDlcsCmd()),
 		&cobra.Command{
 			Use:   "validate-json <json> <schema>",
 			Short: "Validate a JSON file against a JSON Schema",
@@ -116,10 +149,24 @@ func main() {
 		},
 		&cobra.Command{
 			Use:   "validate",
-			Short: "Validate the official mods_registry.json against the schema",
+			Short: "Validate mods_registry.json, dlc_load.json and game_data.json against the embedded schemas",
 			RunE: func(cmd *cobra.Command, args []string) error {
-				// TODO: Detect official registry path dynamically
-				return mods.ValidateJSONSchema("/path/to/official/mods_registry.json", "mods_registry.schema.json")
+				settingsPath := findSettingsPath()
+				invalid := 0
+				for _, file := range mods.LauncherFiles {
+					path := filepath.Join(settingsPath, file)
+					if err := mods.ValidateLauncherFile(path); err != nil {
+						invalid++
+						prettylog.PrintError("validate", err, file+" is invalid", false)
+						continue
+					}
+					prettylog.PrintPretty("validate", path+" is valid", prettylog.LogInfo)
+				}
+				if invalid > 0 {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("%d of %d launcher files are invalid", invalid, len(mods.LauncherFiles))
+				}
+				return nil
 			},
 		},
 		&cobra.Command{
@@ -127,14 +174,51 @@ func main() {
 			Short: "Backup the official mods_registry.json",
 			Args:  cobra.ExactArgs(1),
 			RunE: func(cmd *cobra.Command, args []string) error {
-				// TODO: Detect official registry path dynamically
-				return mods.BackupFile("/path/to/official/mods_registry.json", args[0])
+				return mods.BackupFile(filepath.Join(findSettingsPath(), mods.ModsRegistryFile), args[0])
 			},
 		},
 	)
 
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl+C cancels the descriptor reads of a running sort before anything is written. The
+	// signal is only caught once, so a second Ctrl+C ends commands that do not watch ctx.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// gameProfile returns the profile selected with --game.
+func gameProfile() config.GameProfile {
+	profile, err := config.LookupGame(gameKey)
+	if err != nil {
+		prettylog.PrintError("main", err, "Invalid --game", true)
+	}
+	return profile
+}
+
+// findSettingsPath locates the settings directory of the selected game.
+func findSettingsPath() string {
+	profile := gameProfile()
+	settingsPath, err := profile.FindSettingsPath(mods.ModsRegistryFile)
+	if err != nil {
+		prettylog.PrintError("main", err, fmt.Sprintf("Unable to locate %s", mods.ModsRegistryFile), true)
+	}
+	prettylog.PrintPretty("main", fmt.Sprintf("Found %s settings at %s", profile.Name, settingsPath), prettylog.LogInfo)
+	return settingsPath
+}
+
+// loadPlayset locates the settings directory of the selected game and loads its launcher files.
+func loadPlayset() *mods.Playset {
+	playset, err := mods.LoadPlayset(findSettingsPath())
+	if err != nil {
+		prettylog.PrintError("main", err, "Could not load launcher files", true)
+	}
+	playset.Rules = mods.RulesForGame(gameProfile().Key)
+	return playset
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	"stellaris-mod-sorter-go/internal/steam"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newSavesCmd builds the command checking the save games against the game version and DLCs.
+func newSavesCmd() *cobra.Command {
+	var gameDir, gameVersion string
+	var strict bool
+	cmd := &cobra.Command{
+		Use:   "saves [save...]",
+		Short: "List the save games and warn about those whose version or required DLCs do not match the current setup",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			install := readGameInstall("saves", gameDir)
+			if gameVersion != "" {
+				install.Version = gameVersion
+			}
+			if install.Version == "" {
+				prettylog.PrintPretty("saves", "Game version unknown, pass --game-dir or --game-version to check it", prettylog.LogWarning)
+			}
+			if len(install.Dlcs) == 0 {
+				prettylog.PrintPretty("saves", "No DLCs found in the game folder, skipping the DLC checks", prettylog.LogWarning)
+			}
+
+			saves, err := mods.ListSaves(playset.SettingsPath)
+			if err != nil {
+				return err
+			}
+			saves = filterSaves(saves, args)
+			if len(saves) == 0 {
+				prettylog.PrintPretty("saves", "No save games found in "+filepath.Join(playset.SettingsPath, mods.SaveGamesDir), prettylog.LogInfo)
+				return nil
+			}
+			out := cmd.OutOrStdout()
+			incompatible := 0
+			for _, path := range saves {
+				rel, _ := filepath.Rel(filepath.Join(playset.SettingsPath, mods.SaveGamesDir), path)
+				meta, err := mods.ReadSaveMeta(path)
+				if err != nil {
+					prettylog.PrintPretty("saves", err.Error(), prettylog.LogWarning)
+					continue
+				}
+				issues := mods.CheckSave(meta, install, playset.DisabledDlcs())
+				status := "ok"
+				if len(issues) > 0 {
+					status = "warn"
+					incompatible++
+				}
+				fmt.Fprintf(out, "%-4s  %-10s %-24s %s\n", status, meta.Date, meta.Version, rel)
+				for _, issue := range issues {
+					prettylog.PrintPretty("saves", rel+": "+issue.Message, prettylog.LogWarning)
+				}
+			}
+			if strict && incompatible > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d save games do not match the current setup", incompatible)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&gameDir, "game-dir", "", "game installation folder (defaults to the Steam install)")
+	cmd.Flags().StringVar(&gameVersion, "game-version", "", "game version to compare against, overriding launcher-settings.json")
+	cmd.Flags().BoolVar(&strict, "strict", false, "exit non-zero when any listed save does not match")
+	return cmd
+}
+
+// readGameInstall reads the game folder gameDir, or the Steam install of the selected game
+// when gameDir is empty. A folder that cannot be read is reported and yields no version or DLCs.
+func readGameInstall(name, gameDir string) mods.GameInstall {
+	if gameDir == "" {
+		if dir, ok := steam.FindAppInstall(steam.DefaultSteamRoots(os.Getenv("HOME")), gameProfile().AppId); ok {
+			gameDir = dir
+		}
+	}
+	var install mods.GameInstall
+	if gameDir != "" {
+		var err error
+		if install, err = mods.ReadGameInstall(gameDir); err != nil {
+			prettylog.PrintPretty(name, "Could not read the game folder "+gameDir+": "+err.Error(), prettylog.LogWarning)
+		}
+	}
+	return install
+}
+
+// filterSaves keeps the saves whose path contains one of names, or all of them without names.
+func filterSaves(saves, names []string) []string {
+	if len(names) == 0 {
+		return saves
+	}
+	var result []string
+	for _, path := range saves {
+		for _, name := range names {
+			if strings.Contains(strings.ToLower(filepath.ToSlash(path)), strings.ToLower(filepath.ToSlash(name))) {
+				result = append(result, path)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/server"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newServeCmd builds the command serving the local HTTP API and web UI.
+func newServeCmd() *cobra.Command {
+	var addr string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a local HTTP API and web UI for managing the load order",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			srv := &http.Server{
+				Addr:        addr,
+				Handler:     server.New(playset.SettingsPath, playset.Rules),
+				BaseContext: func(net.Listener) context.Context { return cmd.Context() },
+			}
+			go func() {
+				<-cmd.Context().Done()
+				srv.Shutdown(context.Background())
+			}()
+			prettylog.PrintPretty("serve", "Listening on http://"+addr, prettylog.LogInfo)
+			if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8642", "address to listen on")
+	return cmd
+}
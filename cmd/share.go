@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newShareCmd builds the command encoding the enabled mods into a share code.
+func newShareCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "share",
+		Short: "Encode the enabled mods, their order and the disabled DLCs into a code to paste to friends",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			playset := loadPlayset()
+			loadOrder := playset.EnabledOrder(mods.GetModList(playset.Registry))
+			code, err := mods.NewShareCode(loadOrder, playset.DisabledDlcs()).Encode()
+			if err != nil {
+				return err
+			}
+			if output != "" {
+				if err := os.WriteFile(output, []byte(code+"\n"), 0644); err != nil {
+					return err
+				}
+				prettylog.PrintPretty("share", fmt.Sprintf("Wrote the code for %d mods to %s", len(loadOrder), output), prettylog.LogInfo)
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), code)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the code to a file instead of printing it")
+	return cmd
+}
+
+// newApplyShareCmd builds the command writing the playset of a share code.
+func newApplyShareCmd() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "apply-share <code|file>",
+		Short: "Enable the mods of a share code in its exact order and apply its disabled DLCs",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text := args[0]
+			if content, err := os.ReadFile(text); err == nil {
+				text = string(content)
+			}
+			code, err := mods.DecodeShareCode(text)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			playset := loadPlayset()
+			found, missing := code.Resolve(mods.GetModList(playset.Registry))
+			for _, m := range missing {
+				prettylog.PrintPretty("apply-share", m.String()+" is not installed", prettylog.LogWarning)
+			}
+			prettylog.PrintPretty("apply-share", fmt.Sprintf("%d of %d shared mods installed, %d disabled DLCs",
+				len(found), len(code.Mods), len(code.DisabledDlcs)), prettylog.LogInfo)
+			if dryRun {
+				return nil
+			}
+			playset.Command = "apply-share"
+			playset.ApplyShare(code, found, mods.BakExt)
+			if len(missing) > 0 {
+				prettylog.PrintPretty("apply-share", "Subscribe to the missing mods and apply the code again to match the shared playset", prettylog.LogWarning)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only report the missing mods without writing")
+	return cmd
+}
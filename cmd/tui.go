@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/tui"
+)
+
+// newTuiCmd builds the interactive order review command.
+func newTuiCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tui",
+		Short: "Review and adjust the proposed load order interactively",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session, err := tui.NewSession(cmd.Context(), loadPlayset())
+			if err != nil {
+				return err
+			}
+			return session.Run(os.Stdin, cmd.OutOrStdout())
+		},
+	}
+}
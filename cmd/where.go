@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/config"
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newWhereCmd builds the command listing every settings directory candidate.
+func newWhereCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "where",
+		Short: "List the settings directories checked for the launcher files and the one selected",
+		Run: func(cmd *cobra.Command, args []string) {
+			profile := gameProfile()
+			candidates := profile.Candidates(config.OSEnv(), mods.ModsRegistryFile)
+			selected, ok := config.Selected(candidates)
+			out := cmd.OutOrStdout()
+			for _, c := range candidates {
+				mark := " "
+				status := "missing"
+				if c.Found {
+					status = "found"
+				}
+				if ok && c == selected {
+					mark = "*"
+					status = "selected"
+				}
+				fmt.Fprintf(out, "%s %-8s %-28s %s\n", mark, status, c.Source, c.Path)
+			}
+			if !ok {
+				prettylog.PrintPretty("where", fmt.Sprintf("No %s settings directory with %s found", profile.Name, mods.ModsRegistryFile), prettylog.LogWarning)
+			}
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	"stellaris-mod-sorter-go/internal/steam"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// newWorkshopCmd builds the command reporting Steam workshop install data for each mod.
+func newWorkshopCmd() *cobra.Command {
+	var steamRoots []string
+	cmd := &cobra.Command{
+		Use:   "workshop",
+		Short: "Show Steam workshop install and update times for registered mods",
+		Run: func(cmd *cobra.Command, args []string) {
+			playset := loadPlayset()
+			appId := gameProfile().AppId
+			if len(steamRoots) == 0 {
+				steamRoots = steam.DefaultSteamRoots(os.Getenv("HOME"))
+			}
+			items, libraries := steam.FindWorkshopItems(steamRoots, mods.WorkshopLibraries(playset.Registry), appId)
+			if len(libraries) == 0 {
+				prettylog.PrintPretty("workshop", "No appworkshop_"+appId+".acf found in any Steam library", prettylog.LogWarning)
+				return
+			}
+			for _, library := range libraries {
+				prettylog.PrintPretty("workshop", "Read workshop manifest of "+library, prettylog.LogInfo)
+			}
+
+			modList := mods.GetModList(playset.Registry)
+			mods.AttachWorkshopInfo(modList, items)
+			out := cmd.OutOrStdout()
+			for _, mod := range modList {
+				if mod.Workshop == nil {
+					continue
+				}
+				fmt.Fprintf(out, "%-12s %10s  updated %s  touched %s  %s\n",
+					mod.SteamId, formatSize(mod.Workshop.Size),
+					formatTime(mod.Workshop.TimeUpdated), formatTime(mod.Workshop.TimeTouched), mod.Name)
+			}
+			for _, id := range mods.UnregisteredWorkshopItems(playset.Registry, items) {
+				prettylog.PrintPretty("workshop", fmt.Sprintf("Workshop item %s is installed but not in %s", id, mods.ModsRegistryFile), prettylog.LogWarning)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&steamRoots, "steam-root", nil, "Steam installation directory (repeatable, defaults to the usual locations)")
+	return cmd
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(unix, 0).Format("2006-01-02 15:04")
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
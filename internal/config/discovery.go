@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"stellaris-mod-sorter-go/internal/steam"
+)
+
+// Env is the environment and filesystem seen by settings path discovery.
+type Env struct {
+	Getenv   func(string) string
+	Stat     func(string) (os.FileInfo, error)
+	ReadFile func(string) ([]byte, error)
+}
+
+// OSEnv returns an Env backed by the process environment and the real filesystem.
+func OSEnv() Env {
+	return Env{Getenv: os.Getenv, Stat: os.Stat, ReadFile: os.ReadFile}
+}
+
+// Candidate is a directory that may hold the launcher files of a game.
+type Candidate struct {
+	Path   string
+	Source string
+	Found  bool
+}
+
+// Candidates returns every settings directory checked for the game, in priority order,
+// marking those that contain modsRegistry.
+func (g GameProfile) Candidates(env Env, modsRegistry string) []Candidate {
+	home := env.Getenv("HOME")
+	if home == "" {
+		home = env.Getenv("USERPROFILE")
+	}
+	dataHome := env.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	paradox := filepath.Join("Paradox Interactive", g.SettingsDir)
+
+	var candidates []Candidate
+	seen := map[string]bool{}
+	add := func(path, source string) {
+		c := filepath.Clean(path)
+		if seen[c] {
+			return
+		}
+		seen[c] = true
+		_, err := env.Stat(filepath.Join(path, modsRegistry))
+		candidates = append(candidates, Candidate{Path: path, Source: source, Found: err == nil})
+	}
+
+	add(".", "working directory")
+	add("..", "parent directory")
+	add(filepath.Join(home, "Documents", paradox), "documents")
+	add(filepath.Join(dataHome, paradox), "XDG data home")
+	add(filepath.Join(home, ".local", "share", paradox), "local share")
+	add(filepath.Join(home, steam.FlatpakSteamDir, ".local", "share", paradox), "Flatpak Steam")
+	for _, library := range g.steamLibraries(env, home) {
+		prefix := filepath.Join(library, "steamapps", "compatdata", g.AppId, "pfx")
+		add(filepath.Join(prefix, "drive_c", "users", "steamuser", "Documents", paradox), "Proton prefix in "+library)
+	}
+	return candidates
+}
+
+// steamLibraries returns every Steam library reachable from the usual Steam roots.
+func (g GameProfile) steamLibraries(env Env, home string) []string {
+	var libraries []string
+	seen := map[string]bool{}
+	for _, root := range steam.SteamRoots(home, env.Getenv) {
+		found := []string{root}
+		if content, err := env.ReadFile(steam.LibraryFoldersPath(root)); err == nil {
+			if node, err := steam.ParseVDF(bytes.NewReader(content)); err == nil {
+				found = steam.ParseLibraryFolders(root, node)
+			}
+		} else if _, err := env.Stat(root); err != nil {
+			continue
+		}
+		for _, library := range found {
+			if c := filepath.Clean(library); !seen[c] {
+				seen[c] = true
+				libraries = append(libraries, library)
+			}
+		}
+	}
+	return libraries
+}
+
+// Selected returns the first candidate that contains the launcher files.
+func Selected(candidates []Candidate) (Candidate, bool) {
+	for _, c := range candidates {
+		if c.Found {
+			return c, true
+		}
+	}
+	return Candidate{}, false
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeEnv serves files from a map and reports every parent directory as existing.
+func fakeEnv(vars map[string]string, files map[string]string) Env {
+	dirs := map[string]bool{}
+	for f := range files {
+		for d := filepath.Dir(f); d != "/" && d != "."; d = filepath.Dir(d) {
+			dirs[d] = true
+		}
+	}
+	return Env{
+		Getenv: func(k string) string { return vars[k] },
+		Stat: func(path string) (os.FileInfo, error) {
+			if _, ok := files[filepath.Clean(path)]; ok || dirs[filepath.Clean(path)] {
+				return nil, nil
+			}
+			return nil, os.ErrNotExist
+		},
+		ReadFile: func(path string) ([]byte, error) {
+			if content, ok := files[filepath.Clean(path)]; ok {
+				return []byte(content), nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+}
+
+func TestCandidates_ProtonPrefixInSecondaryLibrary(t *testing.T) {
+	prefix := "/games/steamapps/compatdata/281990/pfx/drive_c/users/steamuser/Documents/Paradox Interactive/Stellaris"
+	env := fakeEnv(map[string]string{"HOME": "/home/u"}, map[string]string{
+		"/home/u/.steam/steam/steamapps/libraryfolders.vdf": `"libraryfolders" { "1" { "path" "/games" } }`,
+		prefix + "/mods_registry.json":                      "{}",
+	})
+	candidates := Games["stellaris"].Candidates(env, "mods_registry.json")
+	selected, ok := Selected(candidates)
+	if !ok || selected.Path != prefix {
+		t.Fatalf("expected %s to be selected, got %+v", prefix, candidates)
+	}
+	if selected.Source != "Proton prefix in /games" {
+		t.Errorf("unexpected source %q", selected.Source)
+	}
+}
+
+func TestCandidates_Priority(t *testing.T) {
+	env := fakeEnv(map[string]string{"HOME": "/home/u", "XDG_DATA_HOME": "/xdg"}, map[string]string{
+		"/xdg/Paradox Interactive/Hearts of Iron IV/mods_registry.json":                                                  "{}",
+		"/home/u/.var/app/com.valvesoftware.Steam/.local/share/Paradox Interactive/Hearts of Iron IV/mods_registry.json": "{}",
+	})
+	candidates := Games["hoi4"].Candidates(env, "mods_registry.json")
+	found := 0
+	for _, c := range candidates {
+		if c.Found {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("expected 2 candidates found, got %+v", candidates)
+	}
+	if selected, _ := Selected(candidates); selected.Source != "XDG data home" {
+		t.Errorf("expected the XDG data home to win, got %+v", selected)
+	}
+}
+
+func TestCandidates_NoneFound(t *testing.T) {
+	candidates := Games["eu4"].Candidates(fakeEnv(map[string]string{"HOME": "/home/u"}, nil), "mods_registry.json")
+	if _, ok := Selected(candidates); ok {
+		t.Errorf("expected nothing selected, got %+v", candidates)
+	}
+	if len(candidates) == 0 || candidates[0].Path != "." {
+		t.Errorf("expected the working directory to be checked first, got %+v", candidates)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultGame is the profile used when no --game is given.
+const DefaultGame = "stellaris"
+
+// GameProfile describes where a Paradox game keeps its launcher files. The sort rules of each
+// game are in mods.GameRules, by the same Key.
+type GameProfile struct {
+	Key         string
+	Name        string
+	SettingsDir string
+	AppId       string
+}
+
+// Games lists the supported game profiles by key.
+var Games = map[string]GameProfile{
+	"stellaris": {Key: "stellaris", Name: "Stellaris", SettingsDir: "Stellaris", AppId: "281990"},
+	"eu4":       {Key: "eu4", Name: "Europa Universalis IV", SettingsDir: "Europa Universalis IV", AppId: "236850"},
+	"hoi4":      {Key: "hoi4", Name: "Hearts of Iron IV", SettingsDir: "Hearts of Iron IV", AppId: "394360"},
+	"ck3":       {Key: "ck3", Name: "Crusader Kings III", SettingsDir: "Crusader Kings III", AppId: "1158310"},
+	"vic3":      {Key: "vic3", Name: "Victoria 3", SettingsDir: "Victoria 3", AppId: "529340"},
+}
+
+// GameKeys returns the profile keys in sorted order.
+func GameKeys() []string {
+	keys := make([]string, 0, len(Games))
+	for k := range Games {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LookupGame returns the profile for key, case-insensitively.
+func LookupGame(key string) (GameProfile, error) {
+	if profile, ok := Games[strings.ToLower(key)]; ok {
+		return profile, nil
+	}
+	return GameProfile{}, fmt.Errorf("unknown game %q, expected one of %s", key, strings.Join(GameKeys(), ", "))
+}
+
+// FindSettingsPath returns the first of the game's Candidates that contains modsRegistry.
+func (g GameProfile) FindSettingsPath(modsRegistry string) (string, error) {
+	if c, ok := Selected(g.Candidates(OSEnv(), modsRegistry)); ok {
+		return c.Path, nil
+	}
+	return "", os.ErrNotExist
+}
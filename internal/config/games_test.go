@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupGame(t *testing.T) {
+	profile, err := LookupGame("HOI4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.AppId != "394360" || profile.SettingsDir != "Hearts of Iron IV" {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+	if _, err := LookupGame("civ6"); err == nil {
+		t.Error("expected error for unknown game")
+	}
+}
+
+func TestGameKeys(t *testing.T) {
+	keys := GameKeys()
+	if len(keys) != len(Games) || keys[0] != "ck3" {
+		t.Errorf("expected sorted keys, got %v", keys)
+	}
+	for _, k := range keys {
+		if Games[k].Key != k {
+			t.Errorf("profile %q has key %q", k, Games[k].Key)
+		}
+	}
+}
+
+func TestFindSettingsPath_UsesGameDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".local", "share", "Paradox Interactive", "Crusader Kings III")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "games_test_registry.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	found, err := Games["ck3"].FindSettingsPath("games_test_registry.json")
+	if err != nil || found != dir {
+		t.Errorf("expected %s, got %q (%v)", dir, found, err)
+	}
+	if _, err := Games["eu4"].FindSettingsPath("games_test_registry.json"); err == nil {
+		t.Error("expected eu4 lookup to miss the CK3 directory")
+	}
+}
@@ -0,0 +1,143 @@
+package mods
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// BisectFile stores a running bisect inside the settings directory.
+const BisectFile = "mod_sorter_bisect.json"
+
+// Bisect narrows the enabled mods down to the one causing a problem. Original is the
+// enabled_mods list to restore at the end, Suspects the mod IDs that may still cause the
+// problem in load order, and Testing the mod IDs enabled for the current step.
+type Bisect struct {
+	Original []string `json:"original"`
+	Suspects []string `json:"suspects"`
+	Testing  []string `json:"testing"`
+	Steps    int      `json:"steps"`
+}
+
+// StartBisect suspects every mod of loadOrder, the enabled mods in sorter order, and picks
+// the first step. idList is the enabled_mods list to restore at the end.
+func StartBisect(loadOrder []*Mod, data map[string]map[string]interface{}, idList []string) *Bisect {
+	b := &Bisect{Original: append([]string{}, idList...)}
+	for _, mod := range loadOrder {
+		if !contains(b.Suspects, mod.ModId) {
+			b.Suspects = append(b.Suspects, mod.ModId)
+		}
+	}
+	b.next(loadOrder, data)
+	return b
+}
+
+// Done reports whether the suspects cannot be narrowed down further.
+func (b *Bisect) Done() bool {
+	return len(b.Testing) == 0
+}
+
+// Mark records whether the problem showed with the Testing mods enabled, narrows the
+// suspects and picks the next step.
+func (b *Bisect) Mark(bad bool, loadOrder []*Mod, data map[string]map[string]interface{}) {
+	var suspects []string
+	for _, id := range b.Suspects {
+		if contains(b.Testing, id) == bad {
+			suspects = append(suspects, id)
+		}
+	}
+	b.Suspects = suspects
+	b.next(loadOrder, data)
+}
+
+// Enabled returns the enabled_mods list of the current step: the original list restricted
+// to the Testing mods, in its original order.
+func (b *Bisect) Enabled() []string {
+	var ids []string
+	for _, id := range b.Original {
+		if contains(b.Testing, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// next enables the first half of the suspects together with the originally enabled mods
+// they depend on. It clears Testing when the suspects cannot be split any more.
+func (b *Bisect) next(loadOrder []*Mod, data map[string]map[string]interface{}) {
+	b.Testing = nil
+	if len(b.Suspects) <= 1 {
+		return
+	}
+	testing := b.withDependencies(b.Suspects[:len(b.Suspects)/2], loadOrder, data)
+	// Suspects pulled in as dependencies are tested with the half, so the step must leave
+	// at least one suspect disabled to narrow anything down
+	for _, id := range b.Suspects {
+		if !contains(testing, id) {
+			b.Testing = testing
+			b.Steps++
+			return
+		}
+	}
+}
+
+// withDependencies adds the originally enabled dependencies of ids, recursively.
+func (b *Bisect) withDependencies(ids []string, loadOrder []*Mod, data map[string]map[string]interface{}) []string {
+	byId := make(map[string]*Mod, len(loadOrder))
+	for _, mod := range loadOrder {
+		byId[mod.ModId] = mod
+	}
+	deps := NewDependencyIndex(data)
+	result := append([]string{}, ids...)
+	for i := 0; i < len(result); i++ {
+		mod, ok := byId[result[i]]
+		if !ok {
+			continue
+		}
+		for _, dep := range mod.Dependencies {
+			match, found := deps.Resolve(dep)
+			if !found {
+				continue
+			}
+			id := registryModId(data[match.Prefer(data, b.Original)])
+			if contains(b.Original, id) && !contains(result, id) {
+				result = append(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// LoadBisect reads the running bisect of settingsPath, or nil when there is none.
+func LoadBisect(settingsPath string) (*Bisect, error) {
+	content, err := os.ReadFile(filepath.Join(settingsPath, BisectFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b Bisect
+	if err := json.Unmarshal(content, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// SaveBisect writes the running bisect to settingsPath.
+func SaveBisect(settingsPath string, b *Bisect) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(settingsPath, BisectFile), content, 0644)
+}
+
+// ClearBisect removes the bisect state of settingsPath.
+func ClearBisect(settingsPath string) error {
+	err := os.Remove(filepath.Join(settingsPath, BisectFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package mods
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// runBisect marks each step bad when culprit is enabled and returns the suspects left.
+func runBisect(t *testing.T, p *Playset, order []string, culprit string) *Bisect {
+	t.Helper()
+	described, _ := p.DescribedMods(context.Background())
+	modList := LoadOrder(described, order)
+	b := StartBisect(modList, p.Registry, p.EnabledIds())
+	for steps := 0; !b.Done(); steps++ {
+		if steps > len(order) {
+			t.Fatalf("bisect did not finish, suspects %v", b.Suspects)
+		}
+		b.Mark(contains(b.Testing, culprit), modList, p.Registry)
+	}
+	return b
+}
+
+func TestBisect_FindsCulprit(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/zulu.mod", "mod/alpha.mod", "mod/sub.mod", "mod/base.mod"]}`,
+		`{"modsOrder": ["h1", "h2", "h3", "h4"]}`)
+	p, _ := LoadPlayset(dir)
+	order := []string{"mod/base.mod", "mod/sub.mod", "mod/alpha.mod", "mod/zulu.mod"}
+	for _, culprit := range order {
+		b := runBisect(t, p, order, culprit)
+		if !reflect.DeepEqual(b.Suspects, []string{culprit}) {
+			t.Errorf("culprit %s: expected it to be the only suspect, got %v", culprit, b.Suspects)
+		}
+		if b.Steps != 2 {
+			t.Errorf("culprit %s: expected 2 steps for 4 mods, got %d", culprit, b.Steps)
+		}
+	}
+}
+
+func TestBisect_KeepsDependenciesEnabled(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/zulu.mod", "mod/base.mod", "mod/sub.mod", "mod/alpha.mod"]}`,
+		`{"modsOrder": ["h1", "h2", "h3", "h4"]}`)
+	p, _ := LoadPlayset(dir)
+	described, _ := p.DescribedMods(context.Background())
+	modList := LoadOrder(described, []string{"mod/alpha.mod", "mod/sub.mod", "mod/base.mod", "mod/zulu.mod"})
+	b := StartBisect(modList, p.Registry, p.EnabledIds())
+	testing := append([]string{}, b.Testing...)
+	sort.Strings(testing)
+	if want := []string{"mod/alpha.mod", "mod/base.mod", "mod/sub.mod"}; !reflect.DeepEqual(testing, want) {
+		t.Errorf("expected Base enabled along with Sub, got %v", testing)
+	}
+	// Enabled keeps the original enabled_mods order
+	if want := []string{"mod/base.mod", "mod/sub.mod", "mod/alpha.mod"}; !reflect.DeepEqual(b.Enabled(), want) {
+		t.Errorf("expected %v, got %v", want, b.Enabled())
+	}
+	b.Mark(false, modList, p.Registry)
+	if !b.Done() || !reflect.DeepEqual(b.Suspects, []string{"mod/zulu.mod"}) {
+		t.Errorf("expected Zulu as the only suspect, got %v", b.Suspects)
+	}
+}
+
+func TestBisect_StateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if b, err := LoadBisect(dir); err != nil || b != nil {
+		t.Fatalf("expected no bisect, got %v, %v", b, err)
+	}
+	want := &Bisect{Original: []string{"a", "b"}, Suspects: []string{"a", "b"}, Testing: []string{"a"}, Steps: 1}
+	if err := SaveBisect(dir, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadBisect(dir)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v, %v", want, got, err)
+	}
+	if err := ClearBisect(dir); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := LoadBisect(dir); b != nil {
+		t.Errorf("expected the state to be removed, got %v", b)
+	}
+}
+
+func TestPlayset_WriteEnabled(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/zulu.mod", "mod/base.mod"]}`,
+		`{"modsOrder": ["h4", "h1"]}`)
+	p, _ := LoadPlayset(dir)
+	p.Command = "bisect step 1"
+	p.WriteEnabled([]string{"mod/base.mod"}, BakExt)
+	reloaded, _ := LoadPlayset(dir)
+	if got := reloaded.EnabledIds(); !reflect.DeepEqual(got, []string{"mod/base.mod"}) {
+		t.Errorf("expected only Base enabled, got %v", got)
+	}
+	if got := reloaded.DisplayOrder(); !reflect.DeepEqual(got, []string{"h4", "h1"}) {
+		t.Errorf("expected modsOrder untouched, got %v", got)
+	}
+	entries, _ := LoadHistory(dir)
+	if len(entries) != 1 || entries[0].Command != "bisect step 1" || len(entries[0].Files) != 1 {
+		t.Errorf("expected one journaled dlc_load change, got %+v", entries)
+	}
+}
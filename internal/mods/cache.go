@@ -0,0 +1,390 @@
+package mods
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// CacheName is the folder of the cache inside the user cache directory. Entries live in a
+// versioned subfolder so a format change starts from an empty cache.
+const (
+	CacheName    = "stellaris-mod-sorter"
+	cacheVersion = "v1"
+)
+
+// Kinds of cache entries, each stored in its own subfolder.
+const (
+	CacheDescriptors = "descriptors"
+	CacheFiles       = "files"
+)
+
+// racyWindow is how recent a modification time must be for the cache to distrust it: a file
+// rewritten again within the same clock tick would keep both its size and its time.
+const racyWindow = 2 * time.Second
+
+// pruneMarker is the file whose modification time records the last prune.
+const pruneMarker = "pruned"
+
+// DefaultCache is the cache used by the descriptor reads, file lists, file hashes and DLC
+// scans. Nil disables caching.
+var DefaultCache *Cache
+
+// Cache stores parsed descriptors and the file lists, hashes and DLC checks of mod folders on
+// disk. Every entry is keyed by its path and checked against the size and modification time
+// of the files it covers, so workshop items updated in place, and archives extracted again,
+// are re-read. A nil *Cache reads everything from disk.
+type Cache struct {
+	Dir string
+}
+
+// CacheStats counts the entries and bytes of one kind of cache entry.
+type CacheStats struct {
+	Kind    string
+	Entries int
+	Bytes   int64
+}
+
+// fileStamp is the size and modification time a cached value was computed from.
+type fileStamp struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"modTime"`
+}
+
+// descriptorEntry holds a parsed descriptor. Descriptors that fail to parse are not cached.
+type descriptorEntry struct {
+	Path       string     `json:"path"`
+	Stamp      fileStamp  `json:"stamp"`
+	Descriptor Descriptor `json:"descriptor"`
+}
+
+// cachedFile holds what was computed from one file of a mod folder: its hash and its DLC
+// checks, each filled in by the first caller needing it.
+type cachedFile struct {
+	Stamp   fileStamp  `json:"stamp"`
+	Hash    string     `json:"hash,omitempty"`
+	Scanned bool       `json:"scanned,omitempty"`
+	Dlcs    []dlcCheck `json:"dlcs,omitempty"`
+}
+
+// filesEntry holds the files of a mod folder. Dirs stamps every folder, so the file list can
+// be reused without walking the folder as long as no folder changed; it is nil when a folder
+// was modified too recently to be trusted.
+type filesEntry struct {
+	Dir   string                `json:"dir"`
+	Dirs  map[string]fileStamp  `json:"dirs,omitempty"`
+	Files map[string]cachedFile `json:"files"`
+}
+
+// walkedFile is a file of a mod folder as returned by walk.
+type walkedFile struct {
+	Rel string
+	cachedFile
+}
+
+// DefaultCacheDir returns the cache folder inside the user cache directory, which is
+// $XDG_CACHE_HOME or ~/.cache on Linux.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, CacheName), nil
+}
+
+// OpenCache returns the cache stored in dir.
+func OpenCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+func stampOf(info fs.FileInfo) fileStamp {
+	return fileStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+}
+
+// trusted reports whether a value computed from a file with stamp may be reused later.
+func (s fileStamp) trusted(now time.Time) bool {
+	return now.Sub(time.Unix(0, s.ModTime)) >= racyWindow
+}
+
+// entryPath returns the file of the entry of kind for key.
+func (c *Cache) entryPath(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, cacheVersion, kind, hex.EncodeToString(sum[:])+".json")
+}
+
+// load reads an entry. A missing or unreadable entry is a miss.
+func (c *Cache) load(kind, key string, v interface{}) bool {
+	content, err := os.ReadFile(c.entryPath(kind, key))
+	return err == nil && json.Unmarshal(content, v) == nil
+}
+
+// store writes an entry through a temporary file, so concurrent readers never see half of it.
+// The cache only saves work, so failing to write is not an error.
+func (c *Cache) store(kind, key string, v interface{}) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	path := c.entryPath(kind, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// ReadDescriptor parses the descriptor file at path, like ReadDescriptor, reusing the parsed
+// descriptor while the file keeps its size and modification time.
+func (c *Cache) ReadDescriptor(path string) (Descriptor, error) {
+	if c == nil {
+		return ReadDescriptor(path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	stamp := stampOf(info)
+	var entry descriptorEntry
+	if c.load(CacheDescriptors, path, &entry) && entry.Path == path && entry.Stamp == stamp {
+		return entry.Descriptor, nil
+	}
+	desc, err := ReadDescriptor(path)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if stamp.trusted(time.Now()) {
+		c.store(CacheDescriptors, path, &descriptorEntry{Path: path, Stamp: stamp, Descriptor: desc})
+	}
+	return desc, nil
+}
+
+// walk lists the files below dirPath in walk order. Cached records are kept while the file
+// keeps its stamp, and fill completes the record of every file, reporting whether it
+// computed anything. A nil *Cache calls fill for every file.
+func (c *Cache) walk(dirPath string, fill func(path string, f *cachedFile) (bool, error)) ([]walkedFile, error) {
+	var entry filesEntry
+	if c == nil || !c.load(CacheFiles, dirPath, &entry) || entry.Dir != dirPath {
+		entry = filesEntry{}
+	}
+	now := time.Now()
+	fresh := filesEntry{Dir: dirPath, Dirs: map[string]fileStamp{}, Files: map[string]cachedFile{}}
+	dirsTrusted := true
+	changed := false
+	var files []walkedFile
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stamp := stampOf(info)
+		if d.IsDir() {
+			fresh.Dirs[rel] = stamp
+			dirsTrusted = dirsTrusted && stamp.trusted(now)
+			return nil
+		}
+		file, ok := entry.Files[rel]
+		if !ok || file.Stamp != stamp {
+			file, changed = cachedFile{Stamp: stamp}, true
+		}
+		if fill != nil {
+			filled, err := fill(path, &file)
+			if err != nil {
+				return err
+			}
+			changed = changed || filled
+		}
+		files = append(files, walkedFile{Rel: rel, cachedFile: file})
+		if !stamp.trusted(now) {
+			// Keep the file listed, but make the next run compute it again
+			file = cachedFile{}
+		}
+		fresh.Files[rel] = file
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !dirsTrusted {
+		fresh.Dirs = nil
+	}
+	if c != nil && (changed || len(fresh.Files) != len(entry.Files) || !reflect.DeepEqual(fresh.Dirs, entry.Dirs)) {
+		c.store(CacheFiles, dirPath, &fresh)
+	}
+	return files, nil
+}
+
+// ModFiles returns the slash separated paths of the files below dirPath, relative to it.
+// The cached list is returned without walking the folder while every folder keeps its stamp,
+// since adding, removing or renaming a file changes the folder holding it.
+func (c *Cache) ModFiles(dirPath string) ([]string, error) {
+	var entry filesEntry
+	if c != nil && c.load(CacheFiles, dirPath, &entry) && entry.Dir == dirPath && entry.Dirs != nil && dirsUnchanged(dirPath, entry.Dirs) {
+		files := make([]string, 0, len(entry.Files))
+		for rel := range entry.Files {
+			files = append(files, rel)
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+	walked, err := c.walk(dirPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, len(walked))
+	for i, f := range walked {
+		files[i] = f.Rel
+	}
+	return files, nil
+}
+
+// dirsUnchanged reports whether every folder of dirs below dirPath still has its stamp.
+func dirsUnchanged(dirPath string, dirs map[string]fileStamp) bool {
+	for rel, stamp := range dirs {
+		info, err := os.Stat(filepath.Join(dirPath, filepath.FromSlash(rel)))
+		if err != nil || !info.IsDir() || stampOf(info) != stamp {
+			return false
+		}
+	}
+	return true
+}
+
+// HashModFiles hashes every file below dirPath like HashModFiles, only reading the files whose
+// size or modification time differ from the cached entry of the folder.
+func (c *Cache) HashModFiles(dirPath string) (map[string]string, error) {
+	if c == nil {
+		return HashModFiles(dirPath)
+	}
+	walked, err := c.walk(dirPath, func(path string, f *cachedFile) (bool, error) {
+		if f.Hash != "" {
+			return false, nil
+		}
+		sum, err := HashFile(path)
+		f.Hash = sum
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(walked))
+	for _, f := range walked {
+		files[f.Rel] = f.Hash
+	}
+	return files, nil
+}
+
+// Prune removes the entries of descriptors and mod folders that no longer exist, such as
+// those of uninstalled mods, and the folders of other cache versions. It returns the number
+// of entries removed.
+func (c *Cache) Prune() (int, error) {
+	versions, err := os.ReadDir(c.Dir)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	for _, v := range versions {
+		if v.IsDir() && v.Name() != cacheVersion {
+			if err := os.RemoveAll(filepath.Join(c.Dir, v.Name())); err != nil {
+				return 0, err
+			}
+		}
+	}
+	removed := 0
+	for _, kind := range []string{CacheDescriptors, CacheFiles} {
+		dir := filepath.Join(c.Dir, cacheVersion, kind)
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			var key struct {
+				Path string `json:"path"`
+				Dir  string `json:"dir"`
+			}
+			content, err := os.ReadFile(path)
+			if err == nil && json.Unmarshal(content, &key) == nil {
+				source := key.Path
+				if kind == CacheFiles {
+					source = key.Dir
+				}
+				if _, err := os.Stat(source); source != "" && !os.IsNotExist(err) {
+					continue
+				}
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// PruneIfDue runs Prune when the last prune is older than interval, so the cache does not
+// grow with every mod ever installed without every run paying for the check.
+func (c *Cache) PruneIfDue(interval time.Duration) (int, error) {
+	marker := filepath.Join(c.Dir, cacheVersion, pruneMarker)
+	if info, err := os.Stat(marker); err == nil && time.Since(info.ModTime()) < interval {
+		return 0, nil
+	}
+	removed, err := c.Prune()
+	if err != nil {
+		return removed, err
+	}
+	if err := os.MkdirAll(filepath.Dir(marker), 0755); err != nil {
+		return removed, err
+	}
+	return removed, os.WriteFile(marker, nil, 0644)
+}
+
+// Stats counts the entries of every kind.
+func (c *Cache) Stats() ([]CacheStats, error) {
+	var stats []CacheStats
+	for _, kind := range []string{CacheDescriptors, CacheFiles} {
+		s := CacheStats{Kind: kind}
+		entries, err := os.ReadDir(filepath.Join(c.Dir, cacheVersion, kind))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil || filepath.Ext(e.Name()) != ".json" {
+				continue
+			}
+			s.Entries++
+			s.Bytes += info.Size()
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// Clear removes the cache folder, every version included.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
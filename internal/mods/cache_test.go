@@ -0,0 +1,203 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeAged writes a file with a modification time old enough for the cache to trust.
+func writeAged(t *testing.T, path, content string, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Now().Add(-age)
+	if err := os.Chtimes(path, when, when); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache_HashModFilesUpdatedInPlace(t *testing.T) {
+	dir := t.TempDir()
+	modDir := filepath.Join(dir, "workshop", "123")
+	writeAged(t, filepath.Join(modDir, "common", "a.txt"), "one", time.Hour)
+	writeAged(t, filepath.Join(modDir, "events", "b.txt"), "two", time.Hour)
+	cache := OpenCache(filepath.Join(dir, "cache"))
+
+	want, _ := HashModFiles(modDir)
+	if got, err := cache.HashModFiles(modDir); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v, %v", want, got, err)
+	}
+	if got, _ := cache.HashModFiles(modDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the cached hashes %v, got %v", want, got)
+	}
+
+	// The workshop item updates in place: same size, newer time, a file removed and one added
+	writeAged(t, filepath.Join(modDir, "common", "a.txt"), "uno", 30*time.Minute)
+	os.Remove(filepath.Join(modDir, "events", "b.txt"))
+	writeAged(t, filepath.Join(modDir, "gfx", "c.txt"), "three", 30*time.Minute)
+	want, _ = HashModFiles(modDir)
+	if got, _ := cache.HashModFiles(modDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the updated hashes %v, got %v", want, got)
+	}
+}
+
+func TestCache_RecentFilesNotTrusted(t *testing.T) {
+	dir := t.TempDir()
+	modDir := filepath.Join(dir, "mod")
+	path := filepath.Join(modDir, "common", "a.txt")
+	writeAged(t, path, "one", 0)
+	cache := OpenCache(filepath.Join(dir, "cache"))
+	if _, err := cache.HashModFiles(modDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewritten within the same tick: size and time stay, only the content changes
+	info, _ := os.Stat(path)
+	os.WriteFile(path, []byte("two"), 0644)
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	want, _ := HashModFiles(modDir)
+	if got, _ := cache.HashModFiles(modDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected a recently modified file to be hashed again, got %v, want %v", got, want)
+	}
+}
+
+func TestCache_Descriptor(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mod", "descriptor.mod")
+	writeAged(t, path, "name=\"Old\"\nversion=\"1.0\"\n", time.Hour)
+	cache := OpenCache(filepath.Join(dir, "cache"))
+
+	desc, err := cache.ReadDescriptor(path)
+	if err != nil || desc.Name != "Old" {
+		t.Fatalf("expected the descriptor of Old, got %+v, %v", desc, err)
+	}
+	if stats, _ := cache.Stats(); stats[0].Kind != CacheDescriptors || stats[0].Entries != 1 {
+		t.Errorf("expected one cached descriptor, got %+v", stats)
+	}
+
+	writeAged(t, path, "name=\"New\"\nversion=\"2.0\"\n", 30*time.Minute)
+	if desc, _ := cache.ReadDescriptor(path); desc.Name != "New" || desc.Version != "2.0" {
+		t.Errorf("expected the updated descriptor, got %+v", desc)
+	}
+	writeAged(t, path, "name=\"Broken\"\ntags={\n", 20*time.Minute)
+	if _, err := cache.ReadDescriptor(path); err == nil {
+		t.Error("expected the parse error of the broken descriptor")
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := cache.Stats(); stats[0].Entries != 0 || stats[1].Entries != 0 {
+		t.Errorf("expected an empty cache after clear, got %+v", stats)
+	}
+}
+
+// ageDir sets the modification time of a folder old enough for the cache to trust.
+func ageDir(t *testing.T, path string, age time.Duration) {
+	t.Helper()
+	when := time.Now().Add(-age)
+	if err := os.Chtimes(path, when, when); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache_ModFiles(t *testing.T) {
+	dir := t.TempDir()
+	modDir := filepath.Join(dir, "mod")
+	writeAged(t, filepath.Join(modDir, "common", "a.txt"), "one", time.Hour)
+	writeAged(t, filepath.Join(modDir, "b.txt"), "two", time.Hour)
+	ageDir(t, filepath.Join(modDir, "common"), time.Hour)
+	ageDir(t, modDir, time.Hour)
+	cache := OpenCache(filepath.Join(dir, "cache"))
+
+	want := []string{"b.txt", "common/a.txt"}
+	if got, err := cache.ModFiles(modDir); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v, %v", want, got, err)
+	}
+	if got, _ := cache.ModFiles(modDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the cached list %v, got %v", want, got)
+	}
+
+	// Adding a file changes the folder holding it, so the list is read again
+	writeAged(t, filepath.Join(modDir, "common", "c.txt"), "three", time.Hour)
+	ageDir(t, filepath.Join(modDir, "common"), 30*time.Minute)
+	want = []string{"b.txt", "common/a.txt", "common/c.txt"}
+	if got, _ := cache.ModFiles(modDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the updated list %v, got %v", want, got)
+	}
+}
+
+func TestCache_ScanModDlcs(t *testing.T) {
+	dir := t.TempDir()
+	modDir := filepath.Join(dir, "mod")
+	path := filepath.Join(modDir, "events", "e.txt")
+	writeAged(t, path, "e = { trigger = { has_dlc = \"Utopia\" } }\n", time.Hour)
+	DefaultCache = OpenCache(filepath.Join(dir, "cache"))
+	defer func() { DefaultCache = nil }()
+
+	uses, err := ScanModDlcs(modDir)
+	if err != nil || len(uses) != 1 || uses[0].Dlc != "Utopia" || !uses[0].Hard {
+		t.Fatalf("expected a hard Utopia check, got %+v, %v", uses, err)
+	}
+	if cached, _ := ScanModDlcs(modDir); !reflect.DeepEqual(cached, uses) {
+		t.Errorf("expected the cached checks %+v, got %+v", uses, cached)
+	}
+
+	writeAged(t, path, "e = { trigger = { has_dlc = \"Megacorp\" } }\n", 30*time.Minute)
+	if uses, _ := ScanModDlcs(modDir); len(uses) != 1 || uses[0].Dlc != "Megacorp" {
+		t.Errorf("expected the updated script to be scanned again, got %+v", uses)
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept")
+	gone := filepath.Join(dir, "gone")
+	writeAged(t, filepath.Join(kept, "descriptor.mod"), "name=\"Kept\"\n", time.Hour)
+	writeAged(t, filepath.Join(gone, "descriptor.mod"), "name=\"Gone\"\n", time.Hour)
+	cache := OpenCache(filepath.Join(dir, "cache"))
+	for _, modDir := range []string{kept, gone} {
+		cache.ReadDescriptor(ModDescriptorPath(modDir))
+		cache.HashModFiles(modDir)
+	}
+	os.MkdirAll(filepath.Join(cache.Dir, "v0", CacheFiles), 0755)
+
+	// The mod is uninstalled
+	os.RemoveAll(gone)
+	if removed, err := cache.PruneIfDue(24 * time.Hour); err != nil || removed != 2 {
+		t.Fatalf("expected the two entries of the removed mod pruned, got %d, %v", removed, err)
+	}
+	if stats, _ := cache.Stats(); stats[0].Entries != 1 || stats[1].Entries != 1 {
+		t.Errorf("expected the entries of the installed mod kept, got %+v", stats)
+	}
+	if _, err := os.Stat(filepath.Join(cache.Dir, "v0")); !os.IsNotExist(err) {
+		t.Errorf("expected older cache versions removed, got %v", err)
+	}
+
+	os.RemoveAll(kept)
+	if removed, _ := cache.PruneIfDue(24 * time.Hour); removed != 0 {
+		t.Errorf("expected no prune before the interval passed, got %d", removed)
+	}
+	if removed, _ := cache.Prune(); removed != 2 {
+		t.Errorf("expected an explicit prune to run, got %d", removed)
+	}
+}
+
+func TestCache_Nil(t *testing.T) {
+	dir := t.TempDir()
+	writeAged(t, filepath.Join(dir, "descriptor.mod"), "name=\"Plain\"\n", time.Hour)
+	var cache *Cache
+	if desc, err := cache.ReadDescriptor(filepath.Join(dir, "descriptor.mod")); err != nil || desc.Name != "Plain" {
+		t.Errorf("expected a nil cache to read from disk, got %+v, %v", desc, err)
+	}
+	if files, err := cache.HashModFiles(dir); err != nil || len(files) != 1 {
+		t.Errorf("expected one hashed file, got %v, %v", files, err)
+	}
+}
@@ -0,0 +1,119 @@
+package mods
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Top-level folders whose files enter the game checksum and therefore disable
+// achievements and Ironman when changed.
+var checksumFolders = []string{"common", "events", "map", "localisation_synced", "prescripted_countries"}
+
+// Top-level folders that only change presentation and never affect the checksum.
+var cosmeticFolders = []string{"gfx", "sound", "music", "localisation", "interface", "fonts", "flags", "video", "dlc_metadata"}
+
+// Checksum impact classes of a mod.
+const (
+	ImpactChecksum = "checksum"
+	ImpactCosmetic = "cosmetic"
+	ImpactNone     = "none"
+)
+
+// ModChecksum is the checksum impact of one mod.
+type ModChecksum struct {
+	Mod            *Mod
+	Impact         string
+	ChecksumFiles  []string
+	ChecksumHash   string
+	UnknownFolders []string
+}
+
+// ChecksumReport is the checksum impact of a whole load order.
+type ChecksumReport struct {
+	Mods     []ModChecksum
+	Combined string
+}
+
+// IsChecksumFile reports whether a slash separated mod-relative path enters the game checksum.
+func IsChecksumFile(path string) bool {
+	return contains(checksumFolders, topFolder(path))
+}
+
+func topFolder(path string) string {
+	if i := strings.Index(path, "/"); i >= 0 {
+		return strings.ToLower(path[:i])
+	}
+	return ""
+}
+
+// ClassifyChecksum classifies a mod from its file hashes as returned by HashModFiles.
+func ClassifyChecksum(mod *Mod, files map[string]string) ModChecksum {
+	mc := ModChecksum{Mod: mod, Impact: ImpactNone}
+	checksummed := map[string]string{}
+	for path, sum := range files {
+		folder := topFolder(path)
+		switch {
+		case folder == "":
+			continue
+		case contains(checksumFolders, folder):
+			checksummed[path] = sum
+			mc.ChecksumFiles = append(mc.ChecksumFiles, path)
+		case contains(cosmeticFolders, folder):
+			if mc.Impact == ImpactNone {
+				mc.Impact = ImpactCosmetic
+			}
+		default:
+			if !contains(mc.UnknownFolders, folder) {
+				mc.UnknownFolders = append(mc.UnknownFolders, folder)
+			}
+		}
+	}
+	if len(checksummed) > 0 {
+		mc.Impact = ImpactChecksum
+		mc.ChecksumHash = ContentHash(checksummed)
+	}
+	sort.Strings(mc.ChecksumFiles)
+	sort.Strings(mc.UnknownFolders)
+	return mc
+}
+
+// BuildChecksumReport classifies every mod of loadOrder and combines the hashes of their
+// checksummed files in that order, so identical setups produce identical combined checksums.
+func BuildChecksumReport(loadOrder []*Mod, data map[string]map[string]interface{}) (*ChecksumReport, error) {
+	report := &ChecksumReport{}
+	combined := sha256.New()
+	for _, mod := range loadOrder {
+		files := map[string]string{}
+		if dirPath := ModDir(data, mod); dirPath != "" && isDir(dirPath) {
+			var err error
+			if files, err = DefaultCache.HashModFiles(dirPath); err != nil {
+				return nil, err
+			}
+		}
+		mc := ClassifyChecksum(mod, files)
+		if mc.Impact == ImpactChecksum {
+			io.WriteString(combined, mc.ChecksumHash+"\n")
+		}
+		report.Mods = append(report.Mods, mc)
+	}
+	report.Combined = hex.EncodeToString(combined.Sum(nil))
+	return report, nil
+}
+
+// LoadOrder returns the mods of modList in the order of enabled_mods, which is the order the game loads them.
+func LoadOrder(modList []*Mod, idList []string) []*Mod {
+	byId := make(map[string]*Mod, len(modList))
+	for _, mod := range modList {
+		byId[mod.ModId] = mod
+	}
+	var result []*Mod
+	for _, id := range idList {
+		if mod, ok := byId[id]; ok {
+			result = append(result, mod)
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package mods
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClassifyChecksum(t *testing.T) {
+	cosmetic := ClassifyChecksum(&Mod{}, map[string]string{"gfx/a.dds": "1", "localisation/english/a.yml": "2", "descriptor.mod": "3"})
+	if cosmetic.Impact != ImpactCosmetic || len(cosmetic.ChecksumFiles) != 0 {
+		t.Errorf("expected cosmetic, got %+v", cosmetic)
+	}
+	gameplay := ClassifyChecksum(&Mod{}, map[string]string{"common/buildings/a.txt": "1", "gfx/a.dds": "2", "weird/x": "3"})
+	if gameplay.Impact != ImpactChecksum || !reflect.DeepEqual(gameplay.ChecksumFiles, []string{"common/buildings/a.txt"}) {
+		t.Errorf("expected checksum impact, got %+v", gameplay)
+	}
+	if !reflect.DeepEqual(gameplay.UnknownFolders, []string{"weird"}) {
+		t.Errorf("expected unknown folder weird, got %v", gameplay.UnknownFolders)
+	}
+	if ClassifyChecksum(&Mod{}, nil).Impact != ImpactNone {
+		t.Error("expected no impact for empty mod")
+	}
+}
+
+func TestBuildChecksumReport_OrderMatters(t *testing.T) {
+	base := t.TempDir()
+	writeMod(t, filepath.Join(base, "a"), map[string]string{"common/a.txt": "a"})
+	writeMod(t, filepath.Join(base, "b"), map[string]string{"events/b.txt": "b", "gfx/b.dds": "b"})
+	writeMod(t, filepath.Join(base, "c"), map[string]string{"music/c.ogg": "c"})
+	data := map[string]map[string]interface{}{
+		"h1": {"displayName": "A", "gameRegistryId": "mod/a.mod", "dirPath": filepath.Join(base, "a")},
+		"h2": {"displayName": "B", "gameRegistryId": "mod/b.mod", "dirPath": filepath.Join(base, "b")},
+		"h3": {"displayName": "C", "gameRegistryId": "mod/c.mod", "dirPath": filepath.Join(base, "c")},
+	}
+	modList := GetModList(data)
+	first, err := BuildChecksumReport(LoadOrder(modList, []string{"mod/a.mod", "mod/b.mod", "mod/c.mod"}), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Mods) != 3 || first.Mods[2].Impact != ImpactCosmetic {
+		t.Errorf("unexpected report %+v", first.Mods)
+	}
+	again, _ := BuildChecksumReport(LoadOrder(modList, []string{"mod/a.mod", "mod/b.mod", "mod/c.mod"}), data)
+	swapped, _ := BuildChecksumReport(LoadOrder(modList, []string{"mod/b.mod", "mod/a.mod", "mod/c.mod"}), data)
+	withoutCosmetic, _ := BuildChecksumReport(LoadOrder(modList, []string{"mod/a.mod", "mod/b.mod"}), data)
+	if first.Combined != again.Combined || first.Combined != withoutCosmetic.Combined {
+		t.Error("expected combined checksum to be stable and ignore cosmetic mods")
+	}
+	if first.Combined == swapped.Combined {
+		t.Error("expected combined checksum to depend on load order")
+	}
+}
+
+func TestLoadOrder(t *testing.T) {
+	modList := []*Mod{{ModId: "a"}, {ModId: "b"}, {ModId: "c"}}
+	got := LoadOrder(modList, []string{"c", "x", "a"})
+	if len(got) != 2 || got[0].ModId != "c" || got[1].ModId != "a" {
+		t.Errorf("unexpected load order %v", got)
+	}
+}
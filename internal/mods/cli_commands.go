@@ -2,6 +2,7 @@ package mods
 
 import (
 	"fmt"
+	"path/filepath"
 )
 
 // CLICommand represents a command that can be run from the CLI.
@@ -35,12 +36,12 @@ var AvailableCommands = []CLICommand{
 	},
 	{
 		Name:        "validate",
-		Description: "Validate the official mods_registry.json against the schema",
+		Description: "Validate the launcher files of a settings directory against the embedded schemas (usage: validate <settings-dir>)",
 		Handler:     ValidateOfficialRegistryCommand,
 	},
 	{
 		Name:        "backup",
-		Description: "Backup the official mods_registry.json (usage: backup <dst>)",
+		Description: "Backup the mods_registry.json of a settings directory (usage: backup <settings-dir> <dst>)",
 		Handler:     BackupOfficialRegistryCommand,
 	},
 	// Add more commands here as needed
@@ -79,17 +80,18 @@ func CustomStellarisPathCommand(args []string) error {
 	return nil
 }
 
-// ValidateOfficialRegistryCommand validates the official mods_registry.json against the schema.
+// ValidateOfficialRegistryCommand validates the launcher files of a settings directory against the embedded schemas.
 func ValidateOfficialRegistryCommand(args []string) error {
-	// TODO: Detect official registry path dynamically
-	return ValidateJSONSchema("/path/to/official/mods_registry.json", "mods_registry.schema.json")
+	if len(args) < 1 {
+		return fmt.Errorf("usage: validate <settings-dir>")
+	}
+	return ValidateLauncherFiles(args[0])
 }
 
-// BackupOfficialRegistryCommand backs up the official mods_registry.json.
+// BackupOfficialRegistryCommand backs up the mods_registry.json of a settings directory.
 func BackupOfficialRegistryCommand(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("usage: backup <dst>")
+	if len(args) < 2 {
+		return fmt.Errorf("usage: backup <settings-dir> <dst>")
 	}
-	// TODO: Detect official registry path dynamically
-	return BackupFile("/path/to/official/mods_registry.json", args[0])
+	return BackupFile(filepath.Join(args[0], ModsRegistryFile), args[1])
 }
@@ -0,0 +1,194 @@
+package mods
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation describes a load order constraint broken by a given order.
+type Violation struct {
+	Mod   *Mod
+	Kind  string
+	Cause string
+}
+
+// Violation kinds reported by the order checks.
+const (
+	ViolationDependency          = "dependency"
+	ViolationMissingDependency   = "missing-dependency"
+	ViolationAmbiguousDependency = "ambiguous-dependency"
+	ViolationDisabledDependency  = "disabled-dependency"
+	ViolationTagTier             = "tag-tier"
+	ViolationSpecialOrder        = "special-order"
+	ViolationPin                 = "pin"
+)
+
+// CheckDependencyOrder reports every mod placed before one of its dependencies,
+// every dependency that is not in the registry and every dependency matching several mods.
+// Of a dependency installed several times, the copy enabled in idList is the one checked,
+// as the solver does.
+func CheckDependencyOrder(modList []*Mod, data map[string]map[string]interface{}, idList []string) []Violation {
+	index := make(map[string]int, len(modList))
+	for i, mod := range modList {
+		index[mod.HashKey] = i
+	}
+	deps := NewDependencyIndex(data)
+	var violations []Violation
+	for i, mod := range modList {
+		for _, dep := range mod.Dependencies {
+			match, found := deps.Resolve(dep)
+			if !found {
+				violations = append(violations, Violation{
+					Mod:   mod,
+					Kind:  ViolationMissingDependency,
+					Cause: fmt.Sprintf("%s not found in mods_registry", dep),
+				})
+				continue
+			}
+			if match.Ambiguous() {
+				violations = append(violations, Violation{
+					Mod:   mod,
+					Kind:  ViolationAmbiguousDependency,
+					Cause: fmt.Sprintf("%s matches %d mods by %s: %s", dep, len(match.Candidates), match.Method, strings.Join(match.Candidates, ", ")),
+				})
+			}
+			if j, ok := index[match.Prefer(data, idList)]; ok && j > i {
+				violations = append(violations, Violation{
+					Mod:   mod,
+					Kind:  ViolationDependency,
+					Cause: fmt.Sprintf("placed at %d above its dependency %s at %d", i, dep, j),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// CheckDisabledDependencies reports every dependency of an enabled mod of modList that is
+// installed but not enabled. Dependencies of disabled mods are not checked.
+func CheckDisabledDependencies(modList []*Mod, data map[string]map[string]interface{}, idList []string) []Violation {
+	deps := NewDependencyIndex(data)
+	enabled := sliceToSet(idList)
+	var violations []Violation
+	for _, mod := range modList {
+		if _, ok := enabled[mod.ModId]; !ok {
+			continue
+		}
+		for _, dep := range mod.Dependencies {
+			match, found := deps.Resolve(dep)
+			if !found {
+				continue
+			}
+			id := registryModId(data[match.Prefer(data, idList)])
+			if _, ok := enabled[id]; id != "" && !ok {
+				violations = append(violations, Violation{
+					Mod:   mod,
+					Kind:  ViolationDisabledDependency,
+					Cause: fmt.Sprintf("depends on %s, which is installed but disabled", dep),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// TagTier returns 1 for mods carrying one of the early tags, 2 for mods carrying a late or
+// patch tag, and 0 for mods the tag rules do not place.
+func (r SortRules) TagTier(mod *Mod) int {
+	tier := 0
+	for _, t := range mod.Tags {
+		if contains(r.LateTags, t) || (r.PatchTag != "" && t == r.PatchTag) {
+			return 2
+		}
+		if contains(r.EarlyTags, t) {
+			tier = 1
+		}
+	}
+	return tier
+}
+
+// CheckTagTiers reports every mod placed before a mod of a lower tag tier, such as a Fixes
+// mod before a Graphics mod or an untagged mod, by the same relation the solver weighs.
+func (r SortRules) CheckTagTiers(modList []*Mod) []Violation {
+	tiers := make([]int, len(modList))
+	position := make([]int, len(modList))
+	for i, mod := range modList {
+		tiers[i] = r.TagTier(mod)
+		position[i] = i
+	}
+	var violations []Violation
+	for _, c := range brokenTagTiers(tiers, position) {
+		violations = append(violations, Violation{
+			Mod:   modList[c.After],
+			Kind:  ViolationTagTier,
+			Cause: fmt.Sprintf("tag tier %d but placed at %d before %s at %d", tiers[c.After], c.After, modList[c.Before].Name, c.Before),
+		})
+	}
+	return violations
+}
+
+// specialRank returns the index of the first special name contained in the mod name, or -1.
+func (r SortRules) specialRank(mod *Mod) int {
+	for k, name := range r.SpecialNames {
+		if containsSpecial(mod.Name, name) {
+			return k
+		}
+	}
+	return -1
+}
+
+// CheckSpecialOrder reports every special mod placed before a mod that must load ahead of it,
+// such as Dark UI before UI Overhaul Dynamic.
+func (r SortRules) CheckSpecialOrder(modList []*Mod) []Violation {
+	var violations []Violation
+	for i, mod := range modList {
+		rank := r.specialRank(mod)
+		if rank <= 0 {
+			continue
+		}
+		for j := len(modList) - 1; j > i; j-- {
+			if k := r.specialRank(modList[j]); k >= 0 && k < rank {
+				violations = append(violations, Violation{
+					Mod:   mod,
+					Kind:  ViolationSpecialOrder,
+					Cause: fmt.Sprintf("placed at %d before %s at %d", i, modList[j].Name, j),
+				})
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// CheckPins reports every pinned mod that is not at its pinned index. Pins past the end of
+// the list expect the mod last.
+func CheckPins(modList []*Mod, pins map[string]int) []Violation {
+	var violations []Violation
+	for i, mod := range modList {
+		want, ok := pins[mod.HashKey]
+		if !ok {
+			continue
+		}
+		if want >= len(modList) {
+			want = len(modList) - 1
+		}
+		if want < 0 {
+			want = 0
+		}
+		if want != i {
+			violations = append(violations, Violation{
+				Mod:   mod,
+				Kind:  ViolationPin,
+				Cause: fmt.Sprintf("pinned to %d but placed at %d", want, i),
+			})
+		}
+	}
+	return violations
+}
+
+// CheckOrder runs the dependency, tag tier and special order checks of rules over modList.
+func CheckOrder(modList []*Mod, data map[string]map[string]interface{}, idList []string, rules SortRules) []Violation {
+	violations := CheckDependencyOrder(modList, data, idList)
+	violations = append(violations, rules.CheckTagTiers(modList)...)
+	return append(violations, rules.CheckSpecialOrder(modList)...)
+}
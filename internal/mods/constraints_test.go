@@ -0,0 +1,166 @@
+package mods
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckDependencyOrder(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"a": {"displayName": "A"},
+		"b": {"displayName": "B"},
+	}
+	modList := []*Mod{
+		{HashKey: "a", Name: "A", Dependencies: []string{"B", "Missing"}},
+		{HashKey: "b", Name: "B"},
+	}
+	violations := CheckDependencyOrder(modList, data, nil)
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", violations)
+	}
+	if violations[0].Kind != ViolationDependency || violations[1].Kind != ViolationMissingDependency {
+		t.Errorf("unexpected kinds: %v", violations)
+	}
+	modList[0], modList[1] = modList[1], modList[0]
+	modList[1].Dependencies = []string{"B"}
+	if v := CheckDependencyOrder(modList, data, nil); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+}
+
+func TestCheckDependencyOrder_Ambiguous(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"a":  {"displayName": "A"},
+		"b1": {"displayName": "B"},
+		"b2": {"displayName": "B"},
+	}
+	modList := []*Mod{{HashKey: "b1"}, {HashKey: "b2"}, {HashKey: "a", Dependencies: []string{"b"}}}
+	violations := CheckDependencyOrder(modList, data, nil)
+	if len(violations) != 1 || violations[0].Kind != ViolationAmbiguousDependency {
+		t.Errorf("expected one ambiguous dependency, got %v", violations)
+	}
+}
+
+func TestTagTier(t *testing.T) {
+	cases := map[int]*Mod{
+		0: {Tags: []string{"Gameplay"}},
+		1: {Tags: []string{"Graphics", "Gameplay"}},
+		2: {Tags: []string{"Graphics", "Fixes"}},
+	}
+	for want, mod := range cases {
+		if got := StellarisRules.TagTier(mod); got != want {
+			t.Errorf("TagTier(%v) = %d, want %d", mod.Tags, got, want)
+		}
+	}
+	if StellarisRules.TagTier(&Mod{Tags: []string{"Patch"}}) != 2 {
+		t.Error("expected Patch to be late tier")
+	}
+}
+
+func TestCheckDependencyOrder_EnabledDuplicate(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"a":     {"displayName": "A", "gameRegistryId": "mod/a.mod"},
+		"local": {"displayName": "B", "gameRegistryId": "mod/local_b.mod"},
+		"ws":    {"displayName": "B", "gameRegistryId": "mod/ugc_1.mod", "steamId": "1"},
+	}
+	a := &Mod{HashKey: "a", Name: "A", ModId: "mod/a.mod", Dependencies: []string{"B"}}
+	local := &Mod{HashKey: "local", Name: "B", ModId: "mod/local_b.mod"}
+	ws := &Mod{HashKey: "ws", Name: "B", ModId: "mod/ugc_1.mod"}
+	idList := []string{"mod/a.mod", "mod/ugc_1.mod"}
+
+	// The disabled local copy sorts first by hash but loading after A does not matter
+	for _, v := range CheckDependencyOrder([]*Mod{ws, a, local}, data, idList) {
+		if v.Kind == ViolationDependency {
+			t.Errorf("expected the enabled workshop copy to satisfy the dependency, got %v", v)
+		}
+	}
+	solution := Solve([]*Mod{a, local, ws}, data, idList, nil, StellarisRules)
+	for _, v := range CheckDependencyOrder(solution.Order, data, idList) {
+		if v.Kind == ViolationDependency {
+			t.Errorf("expected a solved order %v to pass, got %v", GetModHashKeys(solution.Order), v)
+		}
+	}
+	if v := CheckDependencyOrder([]*Mod{a, ws, local}, data, idList); len(v) != 2 || v[1].Kind != ViolationDependency {
+		t.Errorf("expected A above the enabled copy to violate its dependency, got %v", v)
+	}
+}
+
+func TestRulesForGame(t *testing.T) {
+	if !reflect.DeepEqual(RulesForGame("stellaris"), StellarisRules) {
+		t.Error("expected the Stellaris rules for stellaris")
+	}
+	for _, key := range []string{"hoi4", "unknown"} {
+		if rules := RulesForGame(key); len(rules.SpecialNames) != 0 || rules.PatchTag != "Patch" {
+			t.Errorf("expected the common tag tiers without special order rules for %s, got %+v", key, rules)
+		}
+	}
+}
+
+func TestCheckTagTiers(t *testing.T) {
+	modList := []*Mod{
+		{Name: "Fix", Tags: []string{"Fixes"}},
+		{Name: "Other"},
+		{Name: "Gfx", Tags: []string{"Graphics"}},
+	}
+	violations := StellarisRules.CheckTagTiers(modList)
+	if len(violations) != 1 || violations[0].Mod.Name != "Fix" || violations[0].Kind != ViolationTagTier {
+		t.Errorf("unexpected violations %v", violations)
+	}
+	modList = []*Mod{modList[1], modList[2], modList[0]}
+	if v := StellarisRules.CheckTagTiers(modList); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+}
+
+func TestCheckTagTiers_UntaggedAfterTagged(t *testing.T) {
+	modList := []*Mod{
+		{Name: "Gfx", Tags: []string{"Graphics"}},
+		{Name: "Other"},
+	}
+	violations := StellarisRules.CheckTagTiers(modList)
+	if len(violations) != 1 || violations[0].Mod.Name != "Gfx" || violations[0].Kind != ViolationTagTier {
+		t.Errorf("unexpected violations %v", violations)
+	}
+	solution := Solve(modList, nil, nil, nil, StellarisRules)
+	if got := solution.Order[0].Name; got != "Other" {
+		t.Errorf("solver placed %s first, want Other", got)
+	}
+}
+
+func TestCheckSpecialOrder(t *testing.T) {
+	modList := []*Mod{{Name: "Dark UI"}, {Name: "UI Overhaul Dynamic"}}
+	if v := StellarisRules.CheckSpecialOrder(modList); len(v) != 1 || v[0].Mod.Name != "Dark UI" {
+		t.Errorf("unexpected violations %v", v)
+	}
+	if v := StellarisRules.CheckSpecialOrder([]*Mod{modList[1], modList[0]}); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+}
+
+func TestCheckPins(t *testing.T) {
+	modList := []*Mod{{HashKey: "a"}, {HashKey: "b"}, {HashKey: "c"}}
+	if v := CheckPins(modList, map[string]int{"a": 0, "c": 99}); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+	if v := CheckPins(modList, map[string]int{"b": 0}); len(v) != 1 || v[0].Kind != ViolationPin {
+		t.Errorf("expected pin violation, got %v", v)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	data := map[string]map[string]interface{}{"b": {"displayName": "B"}}
+	modList := []*Mod{
+		{HashKey: "a", Name: "Dark UI", Dependencies: []string{"B"}, Tags: []string{"Fixes"}},
+		{HashKey: "b", Name: "B", Tags: []string{"Graphics"}},
+		{HashKey: "c", Name: "UI Overhaul Dynamic"},
+	}
+	kinds := map[string]bool{}
+	for _, v := range CheckOrder(modList, data, nil, StellarisRules) {
+		kinds[v.Kind] = true
+	}
+	for _, k := range []string{ViolationDependency, ViolationTagTier, ViolationSpecialOrder} {
+		if !kinds[k] {
+			t.Errorf("expected a %s violation, got %v", k, kinds)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stellaris-mod-sorter-go/internal/paradox"
+)
+
+// Descriptor holds the fields of a descriptor.mod or .mod file.
+type Descriptor struct {
+	Name             string   `json:"name,omitempty"`
+	Path             string   `json:"path,omitempty"`
+	Picture          string   `json:"picture,omitempty"`
+	RemoteFileId     string   `json:"remoteFileId,omitempty"`
+	Version          string   `json:"version,omitempty"`
+	SupportedVersion string   `json:"supportedVersion,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	Dependencies     []string `json:"dependencies,omitempty"`
+}
+
+// ParseDescriptor parses the content of a descriptor.mod or .mod file.
+func ParseDescriptor(content string) (Descriptor, error) {
+	root, err := paradox.ParseString(content)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return Descriptor{
+		Name:             root.String("name"),
+		Path:             root.String("path"),
+		Picture:          root.String("picture"),
+		RemoteFileId:     root.String("remote_file_id"),
+		Version:          root.String("version"),
+		SupportedVersion: root.String("supported_version"),
+		Tags:             root.Strings("tags"),
+		Dependencies:     root.Strings("dependencies"),
+	}, nil
+}
+
+// ReadDescriptor parses the descriptor file at path.
+func ReadDescriptor(path string) (Descriptor, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return ParseDescriptor(string(content))
+}
+
+// ModDir returns the dirPath of a mod's registry entry.
+func ModDir(data map[string]map[string]interface{}, mod *Mod) string {
+	dirPath, _ := data[mod.HashKey]["dirPath"].(string)
+	return dirPath
+}
+
+// ModDescriptorPath returns the descriptor.mod path inside a mod directory.
+func ModDescriptorPath(dirPath string) string {
+	return filepath.Join(dirPath, "descriptor.mod")
+}
+
+// ModFilePath returns the launcher's .mod file of a mod. ModId is usually already relative to the
+// settings directory (mod/ugc_123.mod); bare file names are looked up in the mod folder.
+func ModFilePath(settingsPath string, mod *Mod) string {
+	id := filepath.ToSlash(mod.ModId)
+	if strings.HasPrefix(id, "mod/") {
+		return filepath.Join(settingsPath, filepath.FromSlash(id))
+	}
+	return filepath.Join(settingsPath, "mod", mod.ModId)
+}
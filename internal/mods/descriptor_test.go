@@ -0,0 +1,45 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDescriptor_Example(t *testing.T) {
+	d, err := ReadDescriptor(filepath.Join("..", "..", "example.mod"))
+	if err != nil {
+		t.Fatalf("ReadDescriptor failed: %v", err)
+	}
+	want := Descriptor{
+		Name:             "SomeMod",
+		Path:             "mod/SomeMod",
+		Picture:          "thumbnail.png",
+		RemoteFileId:     "1234567890",
+		SupportedVersion: "v3.12.*",
+		Tags:             []string{"Graphics", "Economy", "Overhaul"},
+		Dependencies:     []string{"othermod", "another mod"},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("got %+v, want %+v", d, want)
+	}
+}
+
+func TestParseDescriptor_Invalid(t *testing.T) {
+	if _, err := ParseDescriptor("tags={\n\"a\"\n"); err == nil {
+		t.Error("expected error for unclosed block")
+	}
+}
+
+func TestModDir(t *testing.T) {
+	dir := t.TempDir()
+	data := map[string]map[string]interface{}{"h1": {"dirPath": dir}}
+	if got := ModDir(data, &Mod{HashKey: "h1"}); got != dir {
+		t.Errorf("expected %s, got %s", dir, got)
+	}
+	os.WriteFile(ModDescriptorPath(dir), []byte(`name="x"`), 0644)
+	if d, err := ReadDescriptor(ModDescriptorPath(dir)); err != nil || d.Name != "x" {
+		t.Errorf("unexpected descriptor %+v, %v", d, err)
+	}
+}
@@ -0,0 +1,198 @@
+package mods
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"stellaris-mod-sorter-go/internal/paradox"
+)
+
+// Kinds of DLC problems reported by CheckDlcUses.
+const (
+	DlcIssueDisabled = "disabled-dlc"
+	DlcIssueMissing  = "missing-dlc"
+)
+
+// dlcTriggers are the triggers testing whether a DLC is active, for the player or the host.
+var dlcTriggers = []string{"has_dlc", "host_has_dlc"}
+
+// softBlocks are the blocks in which a DLC check chooses between alternatives instead of
+// gating the content it belongs to: branches, negations, limits and weight modifiers.
+var softBlocks = []string{
+	"if", "else_if", "else", "limit", "or", "nor", "not", "nand", "switch", "trigger_switch",
+	"modifier", "weight_modifier", "ai_weight", "ai_chance", "weight",
+}
+
+// dlcCheckPattern finds DLC checks in scripts the parser cannot read.
+var dlcCheckPattern = regexp.MustCompile(`(?i)\b(?:host_)?has_dlc\s*=\s*(?:"([^"]*)"|([^\s}]+))`)
+
+// DlcUse summarises the checks of one mod for one DLC. A hard check gates content that is
+// missing without the DLC; a soft one picks an alternative. File and Line locate the first
+// hard check, or the first check when all are soft.
+type DlcUse struct {
+	Dlc    string
+	Hard   bool
+	Checks int
+	File   string
+	Line   int
+}
+
+// ModDlcs lists the DLCs a mod tests for, sorted by name.
+type ModDlcs struct {
+	Mod  *Mod
+	Uses []DlcUse
+}
+
+// DlcIssue is a DLC check of a mod that fails with the current setup.
+type DlcIssue struct {
+	Mod  *Mod
+	Use  DlcUse
+	Kind string
+}
+
+// dlcCheck is one DLC check of a script, as cached per file.
+type dlcCheck struct {
+	Dlc  string `json:"dlc"`
+	Line int    `json:"line"`
+	Hard bool   `json:"hard,omitempty"`
+}
+
+// ScanModDlcs collects the DLC checks of the .txt scripts below dirPath. Scripts the parser
+// rejects are searched line by line and their checks counted as soft, since their context is
+// unknown. Only scripts changed since the last scan are read again, see Cache.
+func ScanModDlcs(dirPath string) ([]DlcUse, error) {
+	files, err := DefaultCache.walk(dirPath, func(path string, f *cachedFile) (bool, error) {
+		if f.Scanned || !strings.EqualFold(filepath.Ext(path), ".txt") {
+			return false, nil
+		}
+		checks, err := scanDlcChecks(path)
+		f.Dlcs, f.Scanned = checks, true
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	uses := map[string]*DlcUse{}
+	for _, f := range files {
+		for _, check := range f.Dlcs {
+			use, ok := uses[check.Dlc]
+			if !ok {
+				use = &DlcUse{Dlc: check.Dlc, File: f.Rel, Line: check.Line}
+				uses[check.Dlc] = use
+			}
+			use.Checks++
+			if check.Hard && !use.Hard {
+				use.Hard, use.File, use.Line = true, f.Rel, check.Line
+			}
+		}
+	}
+	result := make([]DlcUse, 0, len(uses))
+	for _, use := range uses {
+		result = append(result, *use)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Dlc < result[j].Dlc })
+	return result, nil
+}
+
+// scanDlcChecks returns the DLC checks of the script at path in file order.
+func scanDlcChecks(path string) ([]dlcCheck, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var checks []dlcCheck
+	root, err := paradox.ParseString(string(content))
+	if err != nil {
+		for i, line := range splitLines(string(content)) {
+			if c := strings.Index(line, "#"); c >= 0 {
+				line = line[:c]
+			}
+			for _, m := range dlcCheckPattern.FindAllStringSubmatch(line, -1) {
+				checks = append(checks, dlcCheck{Dlc: m[1] + m[2], Line: i + 1})
+			}
+		}
+		return checks, nil
+	}
+	walkDlcChecks(root, false, func(n *paradox.Node, soft bool) {
+		checks = append(checks, dlcCheck{Dlc: n.Value, Line: n.Line, Hard: !soft})
+	})
+	return checks, nil
+}
+
+// walkDlcChecks calls fn for every DLC check below n, telling whether it sits in a soft block.
+// Checks of a scripted parameter such as has_dlc = $DLC$ name no DLC and are skipped.
+func walkDlcChecks(n *paradox.Node, soft bool, fn func(*paradox.Node, bool)) {
+	for _, c := range n.Children {
+		key := strings.ToLower(c.Key)
+		switch {
+		case c.Block:
+			walkDlcChecks(c, soft || contains(softBlocks, key), fn)
+		case contains(dlcTriggers, key) && c.Value != "" && !strings.HasPrefix(c.Value, "$"):
+			fn(c, soft || c.Op == "!=")
+		}
+	}
+}
+
+// ScanDlcs scans every mod of loadOrder that has a folder. It returns the mods testing for
+// at least one DLC, and the scan errors of every mod joined.
+func ScanDlcs(loadOrder []*Mod, data map[string]map[string]interface{}) ([]ModDlcs, error) {
+	var result []ModDlcs
+	var errs []error
+	for _, mod := range loadOrder {
+		dirPath := ModDir(data, mod)
+		if dirPath == "" || !isDir(dirPath) {
+			continue
+		}
+		uses, err := ScanModDlcs(dirPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", mod.Name, err))
+			continue
+		}
+		if len(uses) > 0 {
+			result = append(result, ModDlcs{Mod: mod, Uses: uses})
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// CheckDlcUses reports the DLC checks of scanned that test for a DLC disabled in
+// dlc_load.json or not installed. Like CheckSave it needs the DLC names of install to map the
+// paths of disabledDlcs, and reports nothing without them.
+func CheckDlcUses(scanned []ModDlcs, install GameInstall, disabledDlcs []string) []DlcIssue {
+	if len(install.Dlcs) == 0 {
+		return nil
+	}
+	disabled, installed := dlcNames(install, disabledDlcs)
+	var issues []DlcIssue
+	for _, m := range scanned {
+		for _, use := range m.Uses {
+			switch {
+			case disabled[use.Dlc]:
+				issues = append(issues, DlcIssue{Mod: m.Mod, Use: use, Kind: DlcIssueDisabled})
+			case !installed[use.Dlc]:
+				issues = append(issues, DlcIssue{Mod: m.Mod, Use: use, Kind: DlcIssueMissing})
+			}
+		}
+	}
+	return issues
+}
+
+// dlcNames returns the names of the DLCs disabled in disabledDlcs and of the installed ones.
+func dlcNames(install GameInstall, disabledDlcs []string) (disabled, installed map[string]bool) {
+	disabled = map[string]bool{}
+	for _, path := range disabledDlcs {
+		if name, ok := install.Dlcs[path]; ok {
+			disabled[name] = true
+		}
+	}
+	installed = map[string]bool{}
+	for _, name := range install.Dlcs {
+		installed[name] = true
+	}
+	return disabled, installed
+}
@@ -0,0 +1,73 @@
+package mods
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testDlcScript = `federation_type = {
+	potential = {
+		has_dlc = "Federations"
+	}
+}
+my_event = {
+	trigger = {
+		OR = {
+			host_has_dlc = "Utopia"
+			NOT = { has_dlc = "Megacorp" }
+		}
+	}
+	immediate = {
+		if = {
+			limit = { has_dlc = "Federations" }
+		}
+	}
+}
+my_trigger = { has_dlc = $DLC$ }
+`
+
+func TestScanModDlcs(t *testing.T) {
+	dir := t.TempDir()
+	writeMod(t, dir, map[string]string{
+		"common/federation_types/types.txt": testDlcScript,
+		"events/broken.txt":                 "e = { has_dlc = \"Leviathans Story Pack\" # }\n}}",
+		"localisation/english/l.yml":        "has_dlc = \"Ignored\"",
+	})
+	uses, err := ScanModDlcs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DlcUse{
+		{Dlc: "Federations", Hard: true, Checks: 2, File: "common/federation_types/types.txt", Line: 3},
+		{Dlc: "Leviathans Story Pack", Checks: 1, File: "events/broken.txt", Line: 1},
+		{Dlc: "Megacorp", Checks: 1, File: "common/federation_types/types.txt", Line: 10},
+		{Dlc: "Utopia", Checks: 1, File: "common/federation_types/types.txt", Line: 9},
+	}
+	if !reflect.DeepEqual(uses, want) {
+		t.Errorf("expected %+v, got %+v", want, uses)
+	}
+}
+
+func TestCheckDlcUses(t *testing.T) {
+	mod := &Mod{Name: "Fed Mod"}
+	scanned := []ModDlcs{{Mod: mod, Uses: []DlcUse{
+		{Dlc: "Federations", Hard: true},
+		{Dlc: "Megacorp"},
+		{Dlc: "Utopia"},
+	}}}
+	install := GameInstall{Dlcs: map[string]string{
+		"dlc/dlc021_federations/dlc021.dlc": "Federations",
+		"dlc/dlc008_utopia/dlc008.dlc":      "Utopia",
+	}}
+	issues := CheckDlcUses(scanned, install, []string{"dlc/dlc021_federations/dlc021.dlc"})
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Use.Dlc+" "+issue.Kind)
+	}
+	if want := []string{"Federations " + DlcIssueDisabled, "Megacorp " + DlcIssueMissing}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if issues := CheckDlcUses(scanned, GameInstall{}, []string{"dlc/dlc021_federations/dlc021.dlc"}); issues != nil {
+		t.Errorf("expected no issues without the installed DLC names, got %v", issues)
+	}
+}
@@ -0,0 +1,112 @@
+package mods
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// exampleDependencies adds the submod relations of the bundled example registry,
+// which only lists tags.
+var exampleDependencies = map[string]string{
+	"Better Planet View - Zone Compact Mode": "Better Planet View",
+	"Planetary Diversity - Gaia Worlds":      "Planetary Diversity",
+}
+
+// examplePlayset loads example_registry.json and dlc_load_example.json into a settings
+// directory, writing a descriptor.mod with the registry tags for every mod.
+func examplePlayset(t *testing.T) *Playset {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("..", "..", "example_registry.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data map[string]map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for h, d := range data {
+		modDir := filepath.Join(dir, "mods", h)
+		os.MkdirAll(modDir, 0755)
+		var desc strings.Builder
+		desc.WriteString("name=\"" + d["displayName"].(string) + "\"\ntags={\n")
+		for _, tag := range d["tags"].([]interface{}) {
+			desc.WriteString("\t\"" + tag.(string) + "\"\n")
+		}
+		desc.WriteString("}\n")
+		if dep, ok := exampleDependencies[d["displayName"].(string)]; ok {
+			desc.WriteString("dependencies={\n\t\"" + dep + "\"\n}\n")
+		}
+		os.WriteFile(filepath.Join(modDir, "descriptor.mod"), []byte(desc.String()), 0644)
+		d["dirPath"] = modDir
+	}
+	dlcLoad, dlcLoadPath := ReadJsonOrder(filepath.Join("..", ".."), "dlc_load_example.json")
+	return &Playset{SettingsPath: dir, Registry: data, DlcLoad: dlcLoad, DlcLoadPath: dlcLoadPath, GameData: map[string]interface{}{}, Rules: StellarisRules}
+}
+
+// proposedOrder returns the proposed order of p, failing the test on error.
+func proposedOrder(t *testing.T, p *Playset) []*Mod {
+	t.Helper()
+	modList, err := p.ProposedOrder(context.Background(), p.EnabledIds())
+	if err != nil {
+		t.Fatalf("ProposedOrder failed: %v", err)
+	}
+	return modList
+}
+
+func orderNames(modList []*Mod) string {
+	var names []string
+	for _, mod := range modList {
+		names = append(names, mod.Name)
+	}
+	return strings.Join(names, "\n") + "\n"
+}
+
+func TestProposedOrder_Golden(t *testing.T) {
+	p := examplePlayset(t)
+	got := orderNames(proposedOrder(t, p))
+	golden := filepath.Join("testdata", "example_order.golden")
+	if *update {
+		os.WriteFile(golden, []byte(got), 0644)
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("missing golden file, run go test -update: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("proposed order differs from %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+func TestProposedOrder_Deterministic(t *testing.T) {
+	p := examplePlayset(t)
+	first := orderNames(proposedOrder(t, p))
+	for i := 0; i < 20; i++ {
+		if got := orderNames(proposedOrder(t, p)); got != first {
+			t.Fatalf("run %d produced a different order:\n%s\nfirst:\n%s", i, got, first)
+		}
+	}
+	if _, err := p.VerifyStable(context.Background(), p.EnabledIds()); err != nil {
+		t.Errorf("VerifyStable failed: %v", err)
+	}
+}
+
+func TestGetModList_DuplicateNamesStable(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"h2": {"displayName": "Same", "gameRegistryId": "mod/b.mod"},
+		"h1": {"displayName": "Same", "gameRegistryId": "mod/a.mod"},
+		"h3": {"displayName": "Same", "gameRegistryId": "mod/c.mod"},
+	}
+	for i := 0; i < 20; i++ {
+		if got := strings.Join(GetModHashKeys(GetModList(data)), ","); got != "h1,h2,h3" {
+			t.Fatalf("expected ties broken by hash key, got %s", got)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package mods
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HistoryFile is the append-only journal of every file change made by the tool, kept inside
+// the settings directory. Each line is one HistoryEntry.
+const HistoryFile = "mod_sorter_history.jsonl"
+
+// ErrChangedExternally is returned by Undo when a file no longer matches the journal.
+var ErrChangedExternally = errors.New("changed outside the tool")
+
+// FileChange holds the contents of one file before and after a change.
+// A nil content means the file did not exist.
+type FileChange struct {
+	Path   string  `json:"path"`
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// HistoryEntry is one journaled change. Ids count up from 1 in journal order.
+type HistoryEntry struct {
+	Id      int          `json:"id"`
+	Time    time.Time    `json:"time"`
+	Command string       `json:"command"`
+	Files   []FileChange `json:"files"`
+}
+
+// Change records the contents of files before a write so Commit can journal the difference.
+type Change struct {
+	command string
+	files   []FileChange
+}
+
+// BeginChange reads the current contents of files before command changes them.
+func BeginChange(command string, files ...string) *Change {
+	c := &Change{command: command}
+	for _, file := range files {
+		c.files = append(c.files, FileChange{Path: file, Before: readOptional(file)})
+	}
+	return c
+}
+
+// Commit reads the files again and appends an entry for those that changed to the journal
+// of settingsPath. It returns nil when nothing changed.
+func (c *Change) Commit(settingsPath string) (*HistoryEntry, error) {
+	entry := HistoryEntry{Time: time.Now(), Command: c.command}
+	for _, f := range c.files {
+		f.After = readOptional(f.Path)
+		if !sameContent(f.Before, f.After) {
+			entry.Files = append(entry.Files, f)
+		}
+	}
+	if len(entry.Files) == 0 {
+		return nil, nil
+	}
+	entries, err := LoadHistory(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	entry.Id = len(entries) + 1
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(settingsPath, HistoryFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// LoadHistory reads every entry of the journal. A missing journal yields no entries.
+func LoadHistory(settingsPath string) ([]HistoryEntry, error) {
+	file, err := os.Open(filepath.Join(settingsPath, HistoryFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", HistoryFile, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Undo restores every file touched by entry id and the entries after it to its content
+// before entry id, and journals the restore as a new entry. An id of 0 undoes the last entry.
+// Unless force is set, Undo refuses when a file no longer has the content the journal last
+// wrote to it, because it was changed outside the tool.
+func Undo(settingsPath string, id int, force bool) (*HistoryEntry, error) {
+	entries, err := LoadHistory(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no history in %s", filepath.Join(settingsPath, HistoryFile))
+	}
+	if id == 0 {
+		id = entries[len(entries)-1].Id
+	}
+	start := -1
+	for i, entry := range entries {
+		if entry.Id == id {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("no history entry %d", id)
+	}
+
+	var paths []string
+	before := map[string]*string{}
+	after := map[string]*string{}
+	for _, entry := range entries[start:] {
+		for _, f := range entry.Files {
+			if _, ok := before[f.Path]; !ok {
+				paths = append(paths, f.Path)
+				before[f.Path] = f.Before
+			}
+			after[f.Path] = f.After
+		}
+	}
+	if !force {
+		for _, path := range paths {
+			if !sameContent(readOptional(path), after[path]) {
+				return nil, fmt.Errorf("%s was %w since entry %d", path, ErrChangedExternally, id)
+			}
+		}
+	}
+
+	change := BeginChange(fmt.Sprintf("undo %d", id), paths...)
+	for _, path := range paths {
+		if before[path] == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(*before[path]), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return change.Commit(settingsPath)
+}
+
+// readOptional returns the content of file, or nil when it cannot be read.
+func readOptional(file string) *string {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	s := string(content)
+	return &s
+}
+
+func sameContent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,118 @@
+package mods
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestChange_Commit(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.mod")
+	writeFile(t, a, "1")
+	change := BeginChange("sort", a, b)
+	writeFile(t, a, "2")
+	writeFile(t, b, "new")
+	entry, err := change.Commit(dir)
+	if err != nil || entry == nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if entry.Id != 1 || len(entry.Files) != 2 || entry.Files[1].Before != nil || *entry.Files[0].After != "2" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry, _ := BeginChange("noop", a).Commit(dir); entry != nil {
+		t.Errorf("expected no entry for an unchanged file, got %+v", entry)
+	}
+	entries, err := LoadHistory(dir)
+	if err != nil || len(entries) != 1 || entries[0].Command != "sort" {
+		t.Errorf("unexpected history %+v (%v)", entries, err)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	writeFile(t, a, "a0")
+	writeFile(t, b, "b0")
+	for i, content := range []string{"1", "2"} {
+		change := BeginChange("sort", a, b)
+		writeFile(t, a, "a"+content)
+		if i == 1 {
+			writeFile(t, b, "b"+content)
+		}
+		change.Commit(dir)
+	}
+
+	// Undoing entry 1 also undoes entry 2
+	entry, err := Undo(dir, 1, false)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if readFile(t, a) != "a0" || readFile(t, b) != "b0" {
+		t.Errorf("expected the original contents, got %q and %q", readFile(t, a), readFile(t, b))
+	}
+	if entry.Id != 3 || entry.Command != "undo 1" {
+		t.Errorf("unexpected undo entry: %+v", entry)
+	}
+
+	// The undo itself can be undone
+	if _, err := Undo(dir, 0, false); err != nil {
+		t.Fatalf("Undo of the undo failed: %v", err)
+	}
+	if readFile(t, a) != "a2" || readFile(t, b) != "b2" {
+		t.Errorf("expected the redone contents, got %q and %q", readFile(t, a), readFile(t, b))
+	}
+}
+
+func TestUndo_ChangedExternally(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	change := BeginChange("sort", a)
+	writeFile(t, a, "tool")
+	change.Commit(dir)
+	writeFile(t, a, "launcher")
+
+	if _, err := Undo(dir, 0, false); !errors.Is(err, ErrChangedExternally) {
+		t.Fatalf("expected ErrChangedExternally, got %v", err)
+	}
+	if readFile(t, a) != "launcher" {
+		t.Error("expected the file to be left alone")
+	}
+	if _, err := Undo(dir, 0, true); err != nil {
+		t.Fatalf("forced Undo failed: %v", err)
+	}
+	if _, err := os.Stat(a); !os.IsNotExist(err) {
+		t.Error("expected the file created by the change to be removed")
+	}
+}
+
+func TestUndo_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Undo(dir, 0, false); err == nil {
+		t.Error("expected error without history")
+	}
+	a := filepath.Join(dir, "a.json")
+	change := BeginChange("sort", a)
+	writeFile(t, a, "x")
+	change.Commit(dir)
+	if _, err := Undo(dir, 5, false); err == nil {
+		t.Error("expected error for an unknown entry")
+	}
+}
@@ -2,6 +2,7 @@ package mods
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -36,6 +37,21 @@ func LoadJsonOrder(settingPath, file, bakExt string) (map[string]interface{}, st
 	return jsonData, filePath
 }
 
+// ReadJsonOrder reads a JSON file like LoadJsonOrder but leaves existing backups alone.
+func ReadJsonOrder(settingPath, file string) (map[string]interface{}, string) {
+	filePath := filepath.Join(settingPath, file)
+	jsonData := make(map[string]interface{})
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		prettylog.PrintPretty("ReadJsonOrder", "Loading failed: "+filePath, prettylog.LogWarning)
+		return jsonData, filePath
+	}
+	if err := json.Unmarshal(content, &jsonData); err != nil {
+		prettylog.PrintPretty("ReadJsonOrder", "Loading failed: "+filePath+": "+err.Error(), prettylog.LogWarning)
+	}
+	return jsonData, filePath
+}
+
 // WriteJsonOrder writes a JSON file, backing up the old one.
 func WriteJsonOrder(data map[string]interface{}, file, bakExt string) {
 	// Backup
@@ -94,9 +110,22 @@ func ValidateJSONSchema(jsonPath, schemaPath string) error {
 	return nil
 }
 
-// Standalone function to validate example_registry.json against mods_registry.schema.json for manual check
+// Standalone function to validate example_registry.json against the embedded mods_registry.schema.json for manual check
 func ValidateExampleRegistry() error {
-	return ValidateJSONSchema("example_registry.json", "mods_registry.schema.json")
+	schema, err := LauncherSchema(ModsRegistryFile)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open("example_registry.json")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	doc, err := jsonschema.UnmarshalJSON(f)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(doc)
 }
 
 // BackupFile copies the source file to the destination as a backup.
@@ -112,3 +141,11 @@ func BackupFile(src, dst string) error {
 	}
 	return err
 }
+
+// RestoreBackup copies file+bakExt back over file.
+func RestoreBackup(file, bakExt string) error {
+	if !fileExists(file + bakExt) {
+		return fmt.Errorf("no backup found: %s", file+bakExt)
+	}
+	return BackupFile(file+bakExt, file)
+}
@@ -81,7 +81,7 @@ func TestDecodeJSON_Invalid(t *testing.T) {
 }
 
 func TestValidateExampleRegistry(t *testing.T) {
-	schemaPath := filepath.Join("..", "..", "mods_registry.schema.json")
+	schemaPath := filepath.Join("schemas", "mods_registry.schema.json")
 	jsonPath := filepath.Join("..", "..", "example_registry.json")
 	err := ValidateJSONSchema(jsonPath, schemaPath)
 	if err != nil {
@@ -90,3 +90,19 @@ func TestValidateExampleRegistry(t *testing.T) {
 		t.Log("example_registry.json is valid against mods_registry.schema.json")
 	}
 }
+
+func TestRestoreBackup(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.json")
+	if err := RestoreBackup(file, ".bak"); err == nil {
+		t.Error("expected error without backup")
+	}
+	os.WriteFile(file+".bak", []byte(`{"foo": "old"}`), 0644)
+	os.WriteFile(file, []byte(`{"foo": "new"}`), 0644)
+	if err := RestoreBackup(file, ".bak"); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if content, _ := os.ReadFile(file); string(content) != `{"foo": "old"}` {
+		t.Errorf("expected restored content, got %s", content)
+	}
+}
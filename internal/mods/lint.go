@@ -0,0 +1,146 @@
+package mods
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Severity levels of lint issues.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// maxTags is the launcher's limit on descriptor tags.
+const maxTags = 10
+
+// maxThumbnailSize is the Steam workshop limit for preview images.
+const maxThumbnailSize = 1 << 20
+
+// officialTags is the predefined tag set of the Paradox launcher and Steam workshop.
+var officialTags = []string{
+	"Achievements", "AI", "Balance", "Buildings", "Diplomacy", "Economy", "Events", "Fixes",
+	"Font", "Galaxy Generation", "Gameplay", "Graphics", "Leaders", "Loading Screens", "Military",
+	"Music", "OST", "Overhaul", "Patch", "Political", "Sound", "Spaceships", "Species",
+	"Technologies", "Total Conversion", "Trade", "Translation", "Utilities",
+}
+
+// LintIssue is one problem found in a mod's descriptor or folder layout.
+type LintIssue struct {
+	Mod      string `json:"mod"`
+	HashKey  string `json:"hashKey"`
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+// LintMods checks the descriptor.mod, .mod file and folder of every mod in modList.
+func LintMods(modList []*Mod, data map[string]map[string]interface{}, settingsPath string) []LintIssue {
+	var issues []LintIssue
+	for _, mod := range modList {
+		issues = append(issues, LintMod(mod, data, settingsPath)...)
+	}
+	return issues
+}
+
+// LintMod checks a single mod, see LintMods. It checks the descriptors LoadModDescriptions
+// kept in the mod, and reads them when the mod has none.
+func LintMod(mod *Mod, data map[string]map[string]interface{}, settingsPath string) []LintIssue {
+	var issues []LintIssue
+	report := func(severity, check, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Mod: mod.Name, HashKey: mod.HashKey, Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	dirPath := ModDir(data, mod)
+	if dirPath == "" {
+		report(SeverityError, "dir-path", "dirPath is empty or missing in %s", ModsRegistryFile)
+		return issues
+	}
+	if !isDir(dirPath) {
+		report(SeverityError, "dir-path", "dirPath %s does not exist", dirPath)
+		return issues
+	}
+	descs := mod.Descriptors
+	if descs == nil {
+		descs = readModDescriptors(context.Background(), mod, data, settingsPath, newDirLocks())
+	}
+	desc, err := descs.Descriptor, descs.DescriptorErr
+	if os.IsNotExist(err) {
+		report(SeverityError, "descriptor", "descriptor.mod is missing")
+		return issues
+	}
+	if err != nil {
+		report(SeverityError, "descriptor", "descriptor.mod cannot be parsed: %v", err)
+		return issues
+	}
+	if desc.Name == "" {
+		report(SeverityError, "name", "descriptor.mod has no name")
+	}
+	if len(desc.Tags) > maxTags {
+		report(SeverityWarning, "tag-count", "%d tags, the launcher allows at most %d", len(desc.Tags), maxTags)
+	}
+	for _, t := range desc.Tags {
+		if !contains(officialTags, t) {
+			report(SeverityInfo, "unofficial-tag", "tag %q is not in the official tag set", t)
+		}
+	}
+	if desc.SupportedVersion == "" {
+		report(SeverityWarning, "supported-version", "supported_version is missing")
+	}
+	if strings.Contains(desc.Path, `\`) {
+		report(SeverityWarning, "path", "descriptor.mod path %q uses backslashes", desc.Path)
+	}
+	if desc.Picture == "" {
+		report(SeverityInfo, "thumbnail", "no picture set")
+	} else if info, err := os.Stat(filepath.Join(dirPath, desc.Picture)); err != nil {
+		report(SeverityWarning, "thumbnail", "picture %s not found", desc.Picture)
+	} else if info.Size() > maxThumbnailSize {
+		report(SeverityWarning, "thumbnail", "picture %s is %d KiB, larger than the 1 MiB workshop limit", desc.Picture, info.Size()>>10)
+	}
+
+	modFile := ModFilePath(settingsPath, mod)
+	modDesc, err := descs.ModFile, descs.ModFileErr
+	if os.IsNotExist(err) {
+		report(SeverityWarning, "mod-file", "%s is missing", modFile)
+		return issues
+	}
+	if err != nil {
+		report(SeverityError, "mod-file", "%s cannot be parsed: %v", modFile, err)
+		return issues
+	}
+	if strings.Contains(modDesc.Path, `\`) {
+		report(SeverityWarning, "path", "%s path %q uses backslashes", filepath.Base(modFile), modDesc.Path)
+	}
+	fields := []struct {
+		name      string
+		mod, desc interface{}
+	}{
+		{"name", modDesc.Name, desc.Name},
+		{"version", modDesc.Version, desc.Version},
+		{"supported_version", modDesc.SupportedVersion, desc.SupportedVersion},
+		{"tags", modDesc.Tags, desc.Tags},
+		{"dependencies", modDesc.Dependencies, desc.Dependencies},
+	}
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.mod, f.desc) {
+			report(SeverityWarning, "mod-file-mismatch", "%s differs between %s (%v) and descriptor.mod (%v)", f.name, filepath.Base(modFile), f.mod, f.desc)
+		}
+	}
+	return issues
+}
+
+// CountSeverity returns how many issues have the given severity.
+func CountSeverity(issues []LintIssue, severity string) int {
+	n := 0
+	for _, i := range issues {
+		if i.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
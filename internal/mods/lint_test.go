@@ -0,0 +1,111 @@
+package mods
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func lintChecks(issues []LintIssue) map[string]string {
+	checks := map[string]string{}
+	for _, i := range issues {
+		checks[i.Check] = i.Severity
+	}
+	return checks
+}
+
+func TestLintMod(t *testing.T) {
+	settings := t.TempDir()
+	modDir := filepath.Join(settings, "bad")
+	writeMod(t, modDir, map[string]string{
+		"descriptor.mod": "path=\"mod\\\\bad\"\npicture=\"thumbnail.png\"\ntags={\n" +
+			strings.Repeat("\t\"Gameplay\"\n", 11) + "\t\"Megastructures\"\n}\n",
+	})
+	writeMod(t, filepath.Join(settings, "mod"), map[string]string{"bad.mod": "name=\"Bad\"\npath=\"" + filepath.ToSlash(modDir) + "\"\n"})
+	data := map[string]map[string]interface{}{"h1": {"displayName": "Bad", "gameRegistryId": "mod/bad.mod", "dirPath": modDir}}
+	checks := lintChecks(LintMod(&Mod{HashKey: "h1", Name: "Bad", ModId: "mod/bad.mod"}, data, settings))
+	want := map[string]string{
+		"name":              SeverityError,
+		"tag-count":         SeverityWarning,
+		"unofficial-tag":    SeverityInfo,
+		"supported-version": SeverityWarning,
+		"path":              SeverityWarning,
+		"thumbnail":         SeverityWarning,
+		"mod-file-mismatch": SeverityWarning,
+	}
+	for check, severity := range want {
+		if checks[check] != severity {
+			t.Errorf("expected %s %s, got %v", severity, check, checks)
+		}
+	}
+}
+
+func TestLintMod_Clean(t *testing.T) {
+	settings := t.TempDir()
+	modDir := filepath.Join(settings, "good")
+	desc := "name=\"Good\"\npicture=\"thumbnail.png\"\ntags={\n\t\"Gameplay\"\n}\nsupported_version=\"v4.0.*\"\n"
+	writeMod(t, modDir, map[string]string{"descriptor.mod": desc, "thumbnail.png": "png"})
+	writeMod(t, filepath.Join(settings, "mod"), map[string]string{"good.mod": desc + "path=\"" + filepath.ToSlash(modDir) + "\"\n"})
+	data := map[string]map[string]interface{}{"h1": {"dirPath": modDir}}
+	if issues := LintMod(&Mod{HashKey: "h1", ModId: "mod/good.mod"}, data, settings); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+func TestLintMods_MissingDir(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"h1": {"displayName": "A", "gameRegistryId": "mod/a.mod"},
+		"h2": {"displayName": "B", "gameRegistryId": "mod/b.mod", "dirPath": filepath.Join(t.TempDir(), "gone")},
+	}
+	issues := LintMods(GetModList(data), data, t.TempDir())
+	if len(issues) != 2 || CountSeverity(issues, SeverityError) != 2 {
+		t.Errorf("expected two dir-path errors, got %v", issues)
+	}
+}
+
+func TestModFilePath(t *testing.T) {
+	if got := ModFilePath("/s", &Mod{ModId: "mod/ugc_1.mod"}); filepath.ToSlash(got) != "/s/mod/ugc_1.mod" {
+		t.Errorf("unexpected path %s", got)
+	}
+	if got := ModFilePath("/s", &Mod{ModId: "x.mod"}); filepath.ToSlash(got) != "/s/mod/x.mod" {
+		t.Errorf("unexpected path %s", got)
+	}
+}
+
+func TestGetModDescription_ReadsModFile(t *testing.T) {
+	settings := t.TempDir()
+	modDir := filepath.Join(settings, "m")
+	writeMod(t, modDir, map[string]string{"descriptor.mod": "name=\"M\"\n"})
+	os.MkdirAll(filepath.Join(settings, "mod"), 0755)
+	os.WriteFile(filepath.Join(settings, "mod", "m.mod"), []byte("tags={\n\t\"Fixes\"\n}\n"), 0644)
+	modList := []*Mod{{HashKey: "h1", ModId: "mod/m.mod", SortedKey: "M"}}
+	allTags := map[string][]string{}
+	GetModDescription(context.Background(), modList, map[string]map[string]interface{}{"h1": {"dirPath": modDir}}, allTags, settings)
+	if len(allTags["Fixes"]) != 1 {
+		t.Errorf("expected tags from the .mod file, got %v", allTags)
+	}
+}
+
+func TestLintMod_LoadedDescriptors(t *testing.T) {
+	settings := t.TempDir()
+	modDir := filepath.Join(settings, "m")
+	writeMod(t, modDir, map[string]string{"descriptor.mod": "name=\"M\"\ntags={\"Gameplay\" \"Megastructures\"}\n"})
+	modList := []*Mod{{HashKey: "h1", Name: "M", ModId: "mod/m.mod", SortedKey: "M"}}
+	data := map[string]map[string]interface{}{"h1": {"dirPath": modDir}}
+	GetModDescription(context.Background(), modList, data, map[string][]string{}, settings)
+	if modList[0].Descriptors == nil || modList[0].Descriptors.Descriptor.Name != "M" {
+		t.Fatalf("expected the loaded descriptor kept in the mod, got %+v", modList[0].Descriptors)
+	}
+
+	// Lint checks what the sort read, not the file as it is now
+	os.Remove(ModDescriptorPath(modDir))
+	checks := lintChecks(LintMod(modList[0], data, settings))
+	if checks["unofficial-tag"] != SeverityInfo || checks["mod-file"] != SeverityWarning {
+		t.Errorf("expected the checks of the loaded descriptor, got %v", checks)
+	}
+	if _, ok := checks["descriptor"]; ok {
+		t.Errorf("did not expect descriptor.mod to be read again, got %v", checks)
+	}
+}
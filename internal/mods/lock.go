@@ -0,0 +1,200 @@
+package mods
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLockFile is the name lock writes to when no output is given.
+const DefaultLockFile = "playset.lock.json"
+
+// Kinds of differences reported by CompareLocks.
+const (
+	LockMissing   = "missing"
+	LockExtra     = "extra"
+	LockReordered = "reordered"
+	LockContent   = "content"
+)
+
+// LockedMod pins one enabled mod: its load position, identity, descriptor version and the
+// ContentHash of its archive or files.
+type LockedMod struct {
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	SteamId  string `json:"steamId,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Hash     string `json:"hash"`
+}
+
+// Lock is a manifest of an enabled playset, in sorter order.
+type Lock struct {
+	CreatedAt time.Time   `json:"createdAt"`
+	Mods      []LockedMod `json:"mods"`
+}
+
+// LockDiff is one difference between a reference lock and the local one. Want is empty for
+// extra mods and Got for missing ones.
+type LockDiff struct {
+	Kind string
+	Want LockedMod
+	Got  LockedMod
+}
+
+// lockIgnored lists, in lower case, the folder metadata macOS and Windows leave in mod
+// folders, which is not part of the content of a mod.
+var lockIgnored = map[string]bool{".ds_store": true, "thumbs.db": true, "desktop.ini": true}
+
+// BuildLock hashes every mod of loadOrder, the enabled mods in sorter order.
+func BuildLock(loadOrder []*Mod, data map[string]map[string]interface{}) (*Lock, error) {
+	lock := &Lock{CreatedAt: time.Now(), Mods: []LockedMod{}}
+	for i, mod := range loadOrder {
+		version, hash, err := lockContent(mod, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", mod.Name, err)
+		}
+		lock.Mods = append(lock.Mods, LockedMod{
+			Position: i + 1,
+			Name:     mod.Name,
+			SteamId:  mod.SteamId,
+			Version:  version,
+			Hash:     hash,
+		})
+	}
+	return lock, nil
+}
+
+// lockContent returns the descriptor version and the content hash locked for mod. A mod
+// shipped as an archive is hashed by its archive alone, since the files next to it depend on
+// whether this tool extracted it yet; other mods by their files, without lockIgnored ones.
+func lockContent(mod *Mod, data map[string]map[string]interface{}) (string, string, error) {
+	archivePath, _ := data[mod.HashKey]["archivePath"].(string)
+	if archivePath == "" || !fileExists(archivePath) {
+		snap, err := SnapshotMod(mod, data)
+		if err != nil {
+			return "", "", err
+		}
+		files := make(map[string]string, len(snap.Files))
+		for rel, sum := range snap.Files {
+			if !lockIgnored[strings.ToLower(path.Base(rel))] {
+				files[rel] = sum
+			}
+		}
+		return snap.Descriptor.Version, ContentHash(files), nil
+	}
+	sum, err := HashFile(archivePath)
+	if err != nil {
+		return "", "", err
+	}
+	version := ""
+	if dirPath := ModDir(data, mod); dirPath != "" {
+		if desc, err := DefaultCache.ReadDescriptor(ModDescriptorPath(dirPath)); err == nil {
+			version = desc.Version
+		}
+	}
+	return version, ContentHash(map[string]string{filepath.Base(archivePath): sum}), nil
+}
+
+// LoadLock reads a lock file.
+func LoadLock(path string) (*Lock, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// SaveLock writes lock to path.
+func SaveLock(path string, lock *Lock) error {
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// key identifies a locked mod across machines: by Steam ID, or by name for local mods.
+func (m LockedMod) key() string {
+	if m.SteamId != "" {
+		return "steam:" + m.SteamId
+	}
+	return "name:" + normalizeName(m.Name)
+}
+
+// CompareLocks reports the mods of want missing from got, the extra mods of got, the mods
+// with different content and the fewest mods that must move for got to load in the order of
+// want. Differences are sorted by kind, then by position in want or else in got.
+func CompareLocks(want, got *Lock) []LockDiff {
+	local := make(map[string]LockedMod, len(got.Mods))
+	for _, m := range got.Mods {
+		local[m.key()] = m
+	}
+	shared := map[string]bool{}
+	var diffs []LockDiff
+	for _, w := range want.Mods {
+		g, ok := local[w.key()]
+		if !ok {
+			diffs = append(diffs, LockDiff{Kind: LockMissing, Want: w})
+			continue
+		}
+		shared[w.key()] = true
+		if g.Hash != w.Hash {
+			diffs = append(diffs, LockDiff{Kind: LockContent, Want: w, Got: g})
+		}
+	}
+	for _, g := range got.Mods {
+		if !shared[g.key()] {
+			diffs = append(diffs, LockDiff{Kind: LockExtra, Got: g})
+		}
+	}
+
+	// Mods outside the longest common subsequence of both orders are the reordered ones
+	wantIndex := map[string]int{}
+	var common []LockedMod
+	for _, w := range want.Mods {
+		if shared[w.key()] {
+			wantIndex[w.key()] = len(wantIndex)
+		}
+	}
+	var positions []int
+	for _, g := range got.Mods {
+		if shared[g.key()] {
+			common = append(common, g)
+			positions = append(positions, wantIndex[g.key()])
+		}
+	}
+	wantByKey := make(map[string]LockedMod, len(want.Mods))
+	for _, w := range want.Mods {
+		wantByKey[w.key()] = w
+	}
+	for i, kept := range increasingSubsequence(positions) {
+		if !kept {
+			diffs = append(diffs, LockDiff{Kind: LockReordered, Want: wantByKey[common[i].key()], Got: common[i]})
+		}
+	}
+
+	rank := map[string]int{LockMissing: 0, LockExtra: 1, LockReordered: 2, LockContent: 3}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].Kind != diffs[j].Kind {
+			return rank[diffs[i].Kind] < rank[diffs[j].Kind]
+		}
+		return diffs[i].position() < diffs[j].position()
+	})
+	return diffs
+}
+
+func (d LockDiff) position() int {
+	if d.Want.Position != 0 {
+		return d.Want.Position
+	}
+	return d.Got.Position
+}
@@ -0,0 +1,94 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildLock(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/sub.mod", "mod/base.mod"]}`,
+		`{"modsOrder": ["h1", "h2", "h3", "h4"]}`)
+	os.WriteFile(filepath.Join(dir, "base", "descriptor.mod"), []byte("name=\"Base\"\nversion=\"1.2\"\n"), 0644)
+	p, _ := LoadPlayset(dir)
+	lock, err := BuildLock(p.EnabledOrder(GetModList(p.Registry)), p.Registry)
+	if err != nil {
+		t.Fatalf("BuildLock failed: %v", err)
+	}
+	if len(lock.Mods) != 2 || lock.Mods[0].Name != "Base" || lock.Mods[0].Position != 1 || lock.Mods[0].Version != "1.2" || lock.Mods[1].Name != "Sub" {
+		t.Errorf("unexpected lock %+v", lock.Mods)
+	}
+	if lock.Mods[0].Hash == "" || lock.Mods[0].Hash == lock.Mods[1].Hash {
+		t.Errorf("expected distinct content hashes, got %+v", lock.Mods)
+	}
+
+	path := filepath.Join(t.TempDir(), DefaultLockFile)
+	if err := SaveLock(path, lock); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLock(path)
+	if err != nil || !reflect.DeepEqual(loaded.Mods, lock.Mods) {
+		t.Errorf("expected %+v, got %+v, %v", lock.Mods, loaded, err)
+	}
+}
+
+func TestBuildLock_IgnoresExtractionAndJunk(t *testing.T) {
+	dir := writeOrderPlayset(t, `{"enabled_mods": ["mod/base.mod", "mod/alpha.mod"]}`, `{"modsOrder": []}`)
+	p, _ := LoadPlayset(dir)
+	archive := filepath.Join(dir, "alpha", "alpha.zip")
+	os.WriteFile(archive, []byte("archive"), 0644)
+	p.Registry["h3"]["archivePath"] = archive
+	modList := LoadOrder(GetModList(p.Registry), p.EnabledIds())
+	before, err := BuildLock(modList, p.Registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeMod(t, filepath.Join(dir, "base"), map[string]string{".DS_Store": "x", "gfx/Thumbs.db": "x"})
+	writeMod(t, filepath.Join(dir, "alpha"), map[string]string{"common/extracted.txt": "x"})
+	after, err := BuildLock(modList, p.Registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := CompareLocks(before, after); len(diffs) != 0 {
+		t.Errorf("expected junk files and extracted archives not to change the lock, got %+v", diffs)
+	}
+	os.WriteFile(archive, []byte("updated"), 0644)
+	after, _ = BuildLock(modList, p.Registry)
+	if diffs := CompareLocks(before, after); len(diffs) != 1 || diffs[0].Kind != LockContent || diffs[0].Want.Name != "Alpha" {
+		t.Errorf("expected an updated archive to change the content of Alpha, got %+v", diffs)
+	}
+}
+
+func TestCompareLocks(t *testing.T) {
+	want := &Lock{Mods: []LockedMod{
+		{Position: 1, Name: "A", SteamId: "1", Hash: "a"},
+		{Position: 2, Name: "B", SteamId: "2", Hash: "b"},
+		{Position: 3, Name: "C", SteamId: "3", Hash: "c"},
+		{Position: 4, Name: "Local Patch", Hash: "p"},
+		{Position: 5, Name: "D", SteamId: "4", Hash: "d", Version: "1.0"},
+	}}
+	got := &Lock{Mods: []LockedMod{
+		{Position: 1, Name: "B", SteamId: "2", Hash: "b"},
+		{Position: 2, Name: "A", SteamId: "1", Hash: "a"},
+		{Position: 3, Name: "local patch", Hash: "p"},
+		{Position: 4, Name: "E", SteamId: "5", Hash: "e"},
+		{Position: 5, Name: "D", SteamId: "4", Hash: "d2", Version: "1.1"},
+	}}
+	if diffs := CompareLocks(want, want); len(diffs) != 0 {
+		t.Errorf("expected no differences, got %+v", diffs)
+	}
+	var summary []string
+	for _, d := range CompareLocks(want, got) {
+		name := d.Want.Name
+		if name == "" {
+			name = d.Got.Name
+		}
+		summary = append(summary, d.Kind+" "+name)
+	}
+	expected := []string{"missing C", "extra E", "reordered B", "content D"}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected %v, got %v", expected, summary)
+	}
+}
@@ -0,0 +1,173 @@
+package mods
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"stellaris-mod-sorter-go/internal/paradox"
+)
+
+// Game logs inside the settings directory.
+const (
+	LogsDir      = "logs"
+	ErrorLogFile = "error.log"
+	GameLogFile  = "game.log"
+)
+
+// FileIndex finds the enabled mod providing a file referenced by the game.
+type FileIndex struct {
+	files map[string]*Mod
+	dirs  []indexedDir
+}
+
+type indexedDir struct {
+	prefix string
+	mod    *Mod
+}
+
+// NewFileIndex indexes the files of the mods in loadOrder, which is in enabled_mods order,
+// the order the game loads them in, so a later mod overrides the files of an earlier one.
+func NewFileIndex(loadOrder []*Mod, data map[string]map[string]interface{}) *FileIndex {
+	ix := &FileIndex{files: map[string]*Mod{}}
+	for _, mod := range loadOrder {
+		dirPath := ModDir(data, mod)
+		if dirPath == "" {
+			continue
+		}
+		ix.dirs = append(ix.dirs, indexedDir{prefix: logPathKey(dirPath) + "/", mod: mod})
+		files, _ := DefaultCache.ModFiles(dirPath)
+		for _, rel := range files {
+			ix.files[logPathKey(rel)] = mod
+		}
+	}
+	return ix
+}
+
+// Lookup returns the mod providing path and the path relative to the game or mod folder.
+// Absolute paths are matched against the mod folders, relative ones against the indexed
+// files. The mod is nil when no indexed mod provides the file.
+func (ix *FileIndex) Lookup(path string) (*Mod, string) {
+	key := logPathKey(path)
+	for _, d := range ix.dirs {
+		if strings.HasPrefix(key, d.prefix) {
+			return d.mod, key[len(d.prefix):]
+		}
+	}
+	return ix.files[key], key
+}
+
+// logPathKey normalizes a path for comparison: forward slashes, lower case, no leading "./".
+func logPathKey(path string) string {
+	path = strings.ToLower(filepath.ToSlash(strings.ReplaceAll(path, "\\", "/")))
+	return strings.TrimSuffix(strings.TrimPrefix(path, "./"), "/")
+}
+
+// LogCategory groups a file by its top folder, and by its subfolder inside common.
+func LogCategory(rel string) string {
+	parts := strings.Split(rel, "/")
+	switch {
+	case len(parts) == 1:
+		return "other"
+	case parts[0] == "common" && len(parts) > 2:
+		return parts[0] + "/" + parts[1]
+	default:
+		return parts[0]
+	}
+}
+
+// LogError is one log entry attributed to the mod providing the file it references.
+type LogError struct {
+	Log      string
+	Entry    paradox.LogEntry
+	File     string
+	Category string
+	Mod      *Mod
+}
+
+// AnalyzeLog attributes every entry of the log named name to the first of its files that an
+// indexed mod provides. Entries referencing only other files keep a nil Mod.
+func AnalyzeLog(name string, entries []paradox.LogEntry, ix *FileIndex) []LogError {
+	var result []LogError
+	for _, entry := range entries {
+		e := LogError{Log: name, Entry: entry}
+		for _, file := range entry.Files {
+			mod, rel := ix.Lookup(file)
+			if e.File == "" || (mod != nil && e.Mod == nil) {
+				e.File, e.Mod, e.Category = rel, mod, LogCategory(rel)
+			}
+			if mod != nil {
+				break
+			}
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// ModErrors counts the log errors of one mod by category.
+type ModErrors struct {
+	Name       string         `json:"name"`
+	HashKey    string         `json:"hashKey"`
+	Count      int            `json:"count"`
+	Categories map[string]int `json:"categories"`
+}
+
+// LogReport ranks the mods by their number of log errors.
+type LogReport struct {
+	Mods         []ModErrors `json:"mods"`
+	Unattributed int         `json:"unattributed"`
+	WithoutFile  int         `json:"withoutFile"`
+	Total        int         `json:"total"`
+}
+
+// BuildLogReport counts errs per mod, most errors first. Errors referencing files no enabled
+// mod provides count as unattributed, errors without any file as withoutFile.
+func BuildLogReport(errs []LogError) LogReport {
+	report := LogReport{Mods: []ModErrors{}, Total: len(errs)}
+	byMod := map[*Mod]*ModErrors{}
+	var order []*Mod
+	for _, e := range errs {
+		switch {
+		case e.File == "":
+			report.WithoutFile++
+			continue
+		case e.Mod == nil:
+			report.Unattributed++
+			continue
+		}
+		m, ok := byMod[e.Mod]
+		if !ok {
+			m = &ModErrors{Name: e.Mod.Name, HashKey: e.Mod.HashKey, Categories: map[string]int{}}
+			byMod[e.Mod] = m
+			order = append(order, e.Mod)
+		}
+		m.Count++
+		m.Categories[e.Category]++
+	}
+	for _, mod := range order {
+		report.Mods = append(report.Mods, *byMod[mod])
+	}
+	sort.SliceStable(report.Mods, func(i, j int) bool {
+		if report.Mods[i].Count != report.Mods[j].Count {
+			return report.Mods[i].Count > report.Mods[j].Count
+		}
+		return report.Mods[i].Name < report.Mods[j].Name
+	})
+	return report
+}
+
+// SortedCategories returns the categories of m, most errors first.
+func (m ModErrors) SortedCategories() []string {
+	categories := make([]string, 0, len(m.Categories))
+	for c := range m.Categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if m.Categories[categories[i]] != m.Categories[categories[j]] {
+			return m.Categories[categories[i]] > m.Categories[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	return categories
+}
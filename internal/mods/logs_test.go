@@ -0,0 +1,90 @@
+package mods
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"stellaris-mod-sorter-go/internal/paradox"
+)
+
+func TestAnalyzeLog(t *testing.T) {
+	dir := t.TempDir()
+	baseDir, patchDir := filepath.Join(dir, "base"), filepath.Join(dir, "patch")
+	writeMod(t, baseDir, map[string]string{
+		"events/base_events.txt":         "",
+		"common/buildings/00_shared.txt": "",
+	})
+	writeMod(t, patchDir, map[string]string{
+		"common/buildings/00_shared.txt": "",
+	})
+	data := map[string]map[string]interface{}{
+		"b": {"dirPath": baseDir},
+		"p": {"dirPath": patchDir},
+	}
+	base, patch := &Mod{HashKey: "b", Name: "Base"}, &Mod{HashKey: "p", Name: "Patch"}
+	index := NewFileIndex([]*Mod{base, patch}, data)
+
+	log := `[10:00:00][trigger_impl.cpp:1]: Error: "Unknown trigger" in file: events/base_events.txt line: 3
+[10:00:01][persistent.cpp:2]: Error: "Unexpected token" in file: "common/buildings/00_shared.txt" near line: 1
+[10:00:02][persistent.cpp:2]: Error in file: ` + filepath.ToSlash(filepath.Join(baseDir, "common", "buildings", "00_shared.txt")) + `
+[10:00:03][persistent.cpp:2]: Error in file: common/ship_sizes/00_vanilla.txt
+[10:00:04][gamestate.cpp:3]: Error: something without a file
+`
+	entries, err := paradox.ParseLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := AnalyzeLog(ErrorLogFile, entries, index)
+	wantMods := []*Mod{base, patch, base, nil, nil}
+	for i, e := range errs {
+		if e.Mod != wantMods[i] {
+			t.Errorf("entry %d: expected %v, got %v", i, wantMods[i], e.Mod)
+		}
+	}
+	if errs[1].Category != "common/buildings" || errs[0].Category != "events" {
+		t.Errorf("unexpected categories %q and %q", errs[1].Category, errs[0].Category)
+	}
+
+	report := BuildLogReport(errs)
+	if report.Total != 5 || report.Unattributed != 1 || report.WithoutFile != 1 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if len(report.Mods) != 2 || report.Mods[0].Name != "Base" || report.Mods[0].Count != 2 {
+		t.Fatalf("expected Base first with 2 errors, got %+v", report.Mods)
+	}
+	if got := report.Mods[0].SortedCategories(); len(got) != 2 || report.Mods[0].Categories["events"] != 1 {
+		t.Errorf("unexpected categories: %v", report.Mods[0].Categories)
+	}
+}
+
+func TestNewFileIndex_GameOrderWins(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/zulu.mod", "mod/base.mod"]}`,
+		`{"modsOrder": ["h1", "h4"]}`)
+	for _, name := range []string{"base", "zulu"} {
+		writeMod(t, filepath.Join(dir, name), map[string]string{"common/buildings/00_shared.txt": ""})
+	}
+	p, err := LoadPlayset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := NewFileIndex(LoadOrder(GetModList(p.Registry), p.EnabledIds()), p.Registry)
+	// The game loads enabled_mods in order, so the last entry providing a file overrides it
+	if mod, _ := index.Lookup("common/buildings/00_shared.txt"); mod == nil || mod.Name != "Base" {
+		t.Errorf("expected Base to provide the file, got %v", mod)
+	}
+}
+
+func TestLogCategory(t *testing.T) {
+	for path, want := range map[string]string{
+		"common/buildings/x.txt": "common/buildings",
+		"common/x.txt":           "common",
+		"events/x.txt":           "events",
+		"x.txt":                  "other",
+	} {
+		if got := LogCategory(path); got != want {
+			t.Errorf("LogCategory(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package mods
 
+import "stellaris-mod-sorter-go/internal/steam"
+
 // Mod represents a Stellaris mod and its metadata.
 type Mod struct {
 	HashKey     string
@@ -7,4 +9,8 @@ type Mod struct {
 	ModId       string
 	SortedKey   string
 	Dependencies []string
+	Tags        []string
+	SteamId     string
+	Workshop    *steam.WorkshopItem
+	Descriptors *ModDescriptors
 }
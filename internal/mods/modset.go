@@ -0,0 +1,135 @@
+package mods
+
+// ModSet is an ordered mod list indexed by HashKey, SortedKey and ModId, so the sorter stages
+// can find a mod and move it without scanning or splicing the whole list. Every mod keeps the
+// slot it had in the original list, and moves only shift slot numbers, so mods sharing a key
+// still move independently.
+type ModSet struct {
+	mods   []*Mod
+	order  []int
+	pos    []int
+	slot   map[*Mod]int
+	byHash map[string]int
+	byName map[string][]int
+	byId   map[string][]int
+}
+
+// NewModSet indexes modList. The set keeps its own copy of the order.
+func NewModSet(modList []*Mod) *ModSet {
+	n := len(modList)
+	s := &ModSet{
+		mods:   append([]*Mod{}, modList...),
+		order:  make([]int, n),
+		pos:    make([]int, n),
+		slot:   make(map[*Mod]int, n),
+		byHash: make(map[string]int, n),
+		byName: make(map[string][]int, n),
+		byId:   make(map[string][]int, n),
+	}
+	for i, mod := range s.mods {
+		s.order[i], s.pos[i] = i, i
+		s.slot[mod] = i
+		if _, ok := s.byHash[mod.HashKey]; !ok {
+			s.byHash[mod.HashKey] = i
+		}
+		s.byName[mod.SortedKey] = append(s.byName[mod.SortedKey], i)
+		s.byId[mod.ModId] = append(s.byId[mod.ModId], i)
+	}
+	return s
+}
+
+// Len returns the number of mods in the set.
+func (s *ModSet) Len() int {
+	return len(s.order)
+}
+
+// At returns the mod at position i.
+func (s *ModSet) At(i int) *Mod {
+	return s.mods[s.order[i]]
+}
+
+// Mods returns the mods in their current order.
+func (s *ModSet) Mods() []*Mod {
+	result := make([]*Mod, len(s.order))
+	for i, slot := range s.order {
+		result[i] = s.mods[slot]
+	}
+	return result
+}
+
+// Index returns the current position of the mod ByHash returns.
+func (s *ModSet) Index(h string) (int, bool) {
+	slot, ok := s.byHash[h]
+	if !ok {
+		return -1, false
+	}
+	return s.pos[slot], true
+}
+
+// ByHash returns the mod with HashKey h, or nil. Of mods sharing a HashKey the first in
+// the original order wins.
+func (s *ModSet) ByHash(h string) *Mod {
+	if slot, ok := s.byHash[h]; ok {
+		return s.mods[slot]
+	}
+	return nil
+}
+
+// ByName returns the first mod in the current order whose SortedKey is name, or nil.
+func (s *ModSet) ByName(name string) *Mod {
+	return s.first(s.byName[name])
+}
+
+// ById returns the first mod in the current order with ModId id, or nil.
+func (s *ModSet) ById(id string) *Mod {
+	return s.first(s.byId[id])
+}
+
+func (s *ModSet) first(slots []int) *Mod {
+	found := -1
+	for _, slot := range slots {
+		if found < 0 || s.pos[slot] < s.pos[found] {
+			found = slot
+		}
+	}
+	if found < 0 {
+		return nil
+	}
+	return s.mods[found]
+}
+
+// Move moves the mod at position from so it ends up at position to, shifting the mods in
+// between by one. Only the positions in between are re-indexed.
+func (s *ModSet) Move(from, to int) {
+	if from == to {
+		return
+	}
+	slot := s.order[from]
+	if from < to {
+		copy(s.order[from:to], s.order[from+1:to+1])
+	} else {
+		copy(s.order[to+1:from+1], s.order[to:from])
+	}
+	s.order[to] = slot
+	lo, hi := from, to
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	for i := lo; i <= hi; i++ {
+		s.pos[s.order[i]] = i
+	}
+}
+
+// MoveToEnd moves mod behind every other mod.
+func (s *ModSet) MoveToEnd(mod *Mod) {
+	s.Move(s.pos[s.slot[mod]], len(s.order)-1)
+}
+
+// MoveAfter moves mod directly behind anchor.
+func (s *ModSet) MoveAfter(mod, anchor *Mod) {
+	from, at := s.pos[s.slot[mod]], s.pos[s.slot[anchor]]
+	if from > at {
+		at++
+	}
+	s.Move(from, at)
+}
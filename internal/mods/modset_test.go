@@ -0,0 +1,64 @@
+package mods
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModSet_Lookup(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "a", SortedKey: "Alpha", ModId: "mod/a.mod"},
+		{HashKey: "b", SortedKey: "Beta", ModId: "mod/b.mod"},
+		{HashKey: "c", SortedKey: "Alpha", ModId: "mod/c.mod"},
+	}
+	s := NewModSet(modList)
+	if i, ok := s.Index("c"); !ok || i != 2 {
+		t.Errorf("expected c at 2, got %d, %v", i, ok)
+	}
+	if _, ok := s.Index("x"); ok {
+		t.Error("expected no index for an unknown hash")
+	}
+	if s.ByHash("b") != modList[1] || s.ById("mod/c.mod") != modList[2] || s.ByName("missing") != nil {
+		t.Error("unexpected lookup result")
+	}
+	if s.ByName("Alpha") != modList[0] {
+		t.Errorf("expected the first Alpha in order, got %v", s.ByName("Alpha"))
+	}
+	s.MoveToEnd(modList[0])
+	if s.ByName("Alpha") != modList[2] {
+		t.Errorf("expected the other Alpha to come first after the move, got %v", s.ByName("Alpha"))
+	}
+}
+
+func TestModSet_Move(t *testing.T) {
+	var modList []*Mod
+	for _, h := range []string{"a", "b", "c", "d", "e"} {
+		modList = append(modList, &Mod{HashKey: h})
+	}
+	cases := []struct {
+		move func(s *ModSet)
+		want []string
+	}{
+		{func(s *ModSet) { s.Move(1, 3) }, []string{"a", "c", "d", "b", "e"}},
+		{func(s *ModSet) { s.Move(4, 0) }, []string{"e", "a", "b", "c", "d"}},
+		{func(s *ModSet) { s.MoveToEnd(modList[0]) }, []string{"b", "c", "d", "e", "a"}},
+		{func(s *ModSet) { s.MoveAfter(modList[0], modList[3]) }, []string{"b", "c", "d", "a", "e"}},
+		{func(s *ModSet) { s.MoveAfter(modList[4], modList[1]) }, []string{"a", "b", "e", "c", "d"}},
+		{func(s *ModSet) { s.MoveAfter(modList[2], modList[1]) }, []string{"a", "b", "c", "d", "e"}},
+	}
+	for i, tc := range cases {
+		s := NewModSet(modList)
+		tc.move(s)
+		if got := GetModHashKeys(s.Mods()); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("case %d: expected %v, got %v", i, tc.want, got)
+		}
+		for pos, mod := range s.Mods() {
+			if i, _ := s.Index(mod.HashKey); i != pos {
+				t.Errorf("case %d: %s indexed at %d but placed at %d", i, mod.HashKey, i, pos)
+			}
+		}
+	}
+	if got := GetModHashKeys(modList); !reflect.DeepEqual(got, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("expected the input list untouched, got %v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package mods
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// PinsFile stores the pinned load order positions inside the settings directory.
+const PinsFile = "mod_sorter_pins.json"
+
+// LoadPins reads the pinned positions (HashKey -> index) from the settings directory.
+// A missing file yields an empty map.
+func LoadPins(settingsPath string) (map[string]int, error) {
+	pins := make(map[string]int)
+	content, err := os.ReadFile(filepath.Join(settingsPath, PinsFile))
+	if os.IsNotExist(err) {
+		return pins, nil
+	}
+	if err != nil {
+		return pins, err
+	}
+	if err := json.Unmarshal(content, &pins); err != nil {
+		return make(map[string]int), err
+	}
+	return pins, nil
+}
+
+// SavePins writes the pinned positions to the settings directory.
+func SavePins(settingsPath string, pins map[string]int) error {
+	content, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(settingsPath, PinsFile), content, 0644)
+}
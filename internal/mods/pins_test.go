@@ -0,0 +1,21 @@
+package mods
+
+import "testing"
+
+func TestLoadPins_Missing(t *testing.T) {
+	pins, err := LoadPins(t.TempDir())
+	if err != nil || len(pins) != 0 {
+		t.Errorf("expected empty pins without error, got %v, %v", pins, err)
+	}
+}
+
+func TestSavePins_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if err := SavePins(dir, map[string]int{"h1": 2}); err != nil {
+		t.Fatalf("SavePins failed: %v", err)
+	}
+	pins, err := LoadPins(dir)
+	if err != nil || pins["h1"] != 2 {
+		t.Errorf("expected pin h1 at 2, got %v, %v", pins, err)
+	}
+}
@@ -0,0 +1,294 @@
+package mods
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+// Launcher files read and written inside the Stellaris settings directory.
+const (
+	ModsRegistryFile = "mods_registry.json"
+	DlcLoadFile      = "dlc_load.json"
+	GameDataFile     = "game_data.json"
+	BakExt           = ".bak"
+)
+
+// Playset bundles the launcher files of one Stellaris settings directory.
+type Playset struct {
+	SettingsPath string
+	Registry     map[string]map[string]interface{}
+	DlcLoad      map[string]interface{}
+	DlcLoadPath  string
+	GameData     map[string]interface{}
+	GameDataPath string
+	Rules        SortRules
+	// Minimal makes Solve start from the current order instead of the reverse alphabetical
+	// one, so only the mods breaking a constraint move.
+	Minimal bool
+	// EnabledOnly makes Solve order the enabled mods alone. The disabled ones keep their
+	// relative modsOrder order, after the enabled mods or, with KeepDisabledPositions,
+	// at their modsOrder positions.
+	EnabledOnly           bool
+	KeepDisabledPositions bool
+	// Command names the writes of the playset in the history journal, "sort" when empty.
+	Command string
+}
+
+// LoadPlayset reads mods_registry.json, dlc_load.json and game_data.json without touching any backups.
+func LoadPlayset(settingsPath string) (*Playset, error) {
+	registryPath := filepath.Join(settingsPath, ModsRegistryFile)
+	file, err := os.Open(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", registryPath, err)
+	}
+	defer file.Close()
+	var data map[string]map[string]interface{}
+	if err := DecodeJSON(file, &data); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", registryPath, err)
+	}
+	dlcLoad, dlcLoadPath := ReadJsonOrder(settingsPath, DlcLoadFile)
+	gameData, gameDataPath := ReadJsonOrder(settingsPath, GameDataFile)
+	return &Playset{
+		SettingsPath: settingsPath,
+		Registry:     data,
+		DlcLoad:      dlcLoad,
+		DlcLoadPath:  dlcLoadPath,
+		GameData:     gameData,
+		GameDataPath: gameDataPath,
+		Rules:        StellarisRules,
+	}, nil
+}
+
+// EnabledIds returns the enabled_mods entries of dlc_load.json.
+func (p *Playset) EnabledIds() []string {
+	return stringList(p.DlcLoad["enabled_mods"])
+}
+
+// DisabledDlcs returns the disabled_dlcs entries of dlc_load.json.
+func (p *Playset) DisabledDlcs() []string {
+	return stringList(p.DlcLoad["disabled_dlcs"])
+}
+
+// DisplayOrder returns the modsOrder hash keys of game_data.json.
+func (p *Playset) DisplayOrder() []string {
+	return stringList(p.GameData["modsOrder"])
+}
+
+// DescribedMods returns the registry mods with tags and dependencies read from their descriptors.
+// It fails only when ctx is cancelled.
+func (p *Playset) DescribedMods(ctx context.Context) ([]*Mod, error) {
+	modList := GetModList(p.Registry)
+	if err := GetModDescription(ctx, modList, p.Registry, make(map[string][]string), p.SettingsPath); err != nil {
+		return nil, err
+	}
+	return modList, nil
+}
+
+// EnabledOrder returns the enabled mods of modList in sorter order, which is enabled_mods reversed.
+func (p *Playset) EnabledOrder(modList []*Mod) []*Mod {
+	order := LoadOrder(modList, p.EnabledIds())
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// Solve orders the registry mods with the constraint solver, using the saved pins and the
+// rules of the playset. It fails only when ctx is cancelled while the descriptors are read.
+func (p *Playset) Solve(ctx context.Context, idList []string) (Solution, error) {
+	modList := GetModList(p.Registry)
+	if len(modList) == 0 {
+		return Solution{Order: modList}, nil
+	}
+	if !p.Minimal {
+		modList = TweakModOrder(modList)
+	}
+	if err := GetModDescription(ctx, modList, p.Registry, make(map[string][]string), p.SettingsPath); err != nil {
+		return Solution{}, err
+	}
+	if p.Minimal {
+		modList = p.BaselineOrder(modList)
+	}
+	pins, err := LoadPins(p.SettingsPath)
+	if err != nil {
+		prettylog.PrintPretty("Solve", "Ignoring pins: "+err.Error(), prettylog.LogWarning)
+	}
+	var solution Solution
+	if p.EnabledOnly {
+		solution = p.solveEnabled(modList, idList, pins)
+	} else {
+		solution = Solve(modList, p.Registry, idList, pins, p.Rules)
+	}
+	solution.Broken = append(solution.Broken, CheckDisabledDependencies(solution.Order, p.Registry, idList)...)
+	return solution, nil
+}
+
+// solveEnabled orders the enabled mods of modList and places the disabled ones around them.
+func (p *Playset) solveEnabled(modList []*Mod, idList []string, pins map[string]int) Solution {
+	ids := sliceToSet(idList)
+	var enabled []*Mod
+	for _, mod := range modList {
+		if _, ok := ids[mod.ModId]; ok {
+			enabled = append(enabled, mod)
+		}
+	}
+	solution := Solve(enabled, p.Registry, idList, pins, p.Rules)
+	current := p.CurrentOrder(modList)
+	order := make([]*Mod, 0, len(current))
+	if !p.KeepDisabledPositions {
+		order = append(order, solution.Order...)
+	}
+	next := 0
+	for _, mod := range current {
+		if _, ok := ids[mod.ModId]; !ok {
+			order = append(order, mod)
+		} else if p.KeepDisabledPositions {
+			order = append(order, solution.Order[next])
+			next++
+		}
+	}
+	solution.Order = order
+	return solution
+}
+
+// ProposedOrder returns the order produced by Solve.
+func (p *Playset) ProposedOrder(ctx context.Context, idList []string) ([]*Mod, error) {
+	solution, err := p.Solve(ctx, idList)
+	return solution.Order, err
+}
+
+// VerifyStable runs Solve twice and returns an error describing the first position
+// where the two results differ.
+func (p *Playset) VerifyStable(ctx context.Context, idList []string) (Solution, error) {
+	first, err := p.Solve(ctx, idList)
+	if err != nil {
+		return Solution{}, err
+	}
+	second, err := p.Solve(ctx, idList)
+	if err != nil {
+		return Solution{}, err
+	}
+	a, b := GetModHashKeys(first.Order), GetModHashKeys(second.Order)
+	if len(a) != len(b) {
+		return Solution{}, fmt.Errorf("unstable sort: %d mods in first run, %d in second", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return Solution{}, fmt.Errorf("unstable sort: position %d is %s in the first run and %s in the second", i, first.Order[i].Name, second.Order[i].Name)
+		}
+	}
+	return first, nil
+}
+
+// CurrentOrder returns the mods of modList in the order stored in game_data.json.
+// Mods missing from modsOrder keep their relative order at the end.
+func (p *Playset) CurrentOrder(modList []*Mod) []*Mod {
+	set := NewModSet(modList)
+	result := make([]*Mod, 0, len(modList))
+	seen := map[string]bool{}
+	for _, h := range p.DisplayOrder() {
+		if mod := set.ByHash(h); mod != nil && !seen[h] {
+			result = append(result, mod)
+			seen[h] = true
+		}
+	}
+	for _, mod := range modList {
+		if !seen[mod.HashKey] {
+			result = append(result, mod)
+		}
+	}
+	return result
+}
+
+// BaselineOrder returns modList in the current order, with the enabled mods in their
+// enabled_mods order and the disabled ones at their modsOrder positions.
+func (p *Playset) BaselineOrder(modList []*Mod) []*Mod {
+	current := p.CurrentOrder(modList)
+	var enabled []*Mod
+	inEnabled := map[string]bool{}
+	for _, mod := range p.EnabledOrder(modList) {
+		if !inEnabled[mod.HashKey] {
+			inEnabled[mod.HashKey] = true
+			enabled = append(enabled, mod)
+		}
+	}
+	result := make([]*Mod, 0, len(current))
+	next := 0
+	for _, mod := range current {
+		if inEnabled[mod.HashKey] {
+			mod = enabled[next]
+			next++
+		}
+		result = append(result, mod)
+	}
+	return result
+}
+
+// Write stores modList as the new modsOrder and the enabled subset as enabled_mods,
+// backing up both files through WriteJsonOrder and journaling the change in HistoryFile.
+func (p *Playset) Write(modList []*Mod, idList []string, bakExt string) {
+	for _, file := range []string{p.DlcLoadPath, p.GameDataPath} {
+		if fileExists(file + bakExt) {
+			os.Remove(file + bakExt)
+		}
+	}
+	change := BeginChange(p.command(), p.DlcLoadPath, p.GameDataPath)
+	p.GameData["modsOrder"] = GetModHashKeys(modList)
+	p.DlcLoad["enabled_mods"] = GetModIdsReversed(modList, idList)
+	WriteJsonOrder(p.DlcLoad, p.DlcLoadPath, bakExt)
+	WriteJsonOrder(p.GameData, p.GameDataPath, bakExt)
+	if _, err := change.Commit(p.SettingsPath); err != nil {
+		prettylog.PrintPretty("Write", "Could not journal the change: "+err.Error(), prettylog.LogWarning)
+	}
+}
+
+// WriteEnabled stores idList as enabled_mods as given and leaves modsOrder alone,
+// journaling the change in HistoryFile.
+func (p *Playset) WriteEnabled(idList []string, bakExt string) {
+	if fileExists(p.DlcLoadPath + bakExt) {
+		os.Remove(p.DlcLoadPath + bakExt)
+	}
+	change := BeginChange(p.command(), p.DlcLoadPath)
+	enabled := make([]interface{}, len(idList))
+	for i, id := range idList {
+		enabled[i] = id
+	}
+	p.DlcLoad["enabled_mods"] = enabled
+	WriteJsonOrder(p.DlcLoad, p.DlcLoadPath, bakExt)
+	if _, err := change.Commit(p.SettingsPath); err != nil {
+		prettylog.PrintPretty("WriteEnabled", "Could not journal the change: "+err.Error(), prettylog.LogWarning)
+	}
+}
+
+func (p *Playset) command() string {
+	if p.Command == "" {
+		return "sort"
+	}
+	return p.Command
+}
+
+// RecordState snapshots the enabled mods of modList into StateFile after a successful sort.
+func (p *Playset) RecordState(modList []*Mod, idList []string) error {
+	state, err := TakeSnapshot(modList, idList, p.Registry)
+	if err != nil {
+		return err
+	}
+	return SaveState(p.SettingsPath, state)
+}
+
+// stringList converts a decoded JSON array to a slice of strings, skipping other values.
+func stringList(raw interface{}) []string {
+	var result []string
+	if arr, ok := raw.([]interface{}); ok {
+		for _, v := range arr {
+			if s, ok := v.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,202 @@
+package mods
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writePlayset creates a settings directory with a registry of two mods where "Sub" depends on "Base".
+func writePlayset(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "base")
+	subDir := filepath.Join(dir, "sub")
+	os.Mkdir(baseDir, 0755)
+	os.Mkdir(subDir, 0755)
+	os.WriteFile(filepath.Join(baseDir, "descriptor.mod"), []byte("name=\"Base\"\ntags={\n\t\"Gameplay\"\n}\n"), 0644)
+	os.WriteFile(filepath.Join(subDir, "descriptor.mod"), []byte("name=\"Sub\"\ndependencies={\n\t\"Base\"\n}\n"), 0644)
+	registry := `{
+		"h1": {"displayName": "Base", "gameRegistryId": "mod/base.mod", "dirPath": "` + filepath.ToSlash(baseDir) + `"},
+		"h2": {"displayName": "Sub", "gameRegistryId": "mod/sub.mod", "dirPath": "` + filepath.ToSlash(subDir) + `"}
+	}`
+	os.WriteFile(filepath.Join(dir, ModsRegistryFile), []byte(registry), 0644)
+	os.WriteFile(filepath.Join(dir, DlcLoadFile), []byte(`{"disabled_dlcs": [], "enabled_mods": ["mod/base.mod", "mod/sub.mod"]}`), 0644)
+	os.WriteFile(filepath.Join(dir, GameDataFile), []byte(`{"modsOrder": ["h2", "h1"]}`), 0644)
+	return dir
+}
+
+func TestLoadPlayset(t *testing.T) {
+	dir := writePlayset(t)
+	p, err := LoadPlayset(dir)
+	if err != nil {
+		t.Fatalf("LoadPlayset failed: %v", err)
+	}
+	if !reflect.DeepEqual(p.EnabledIds(), []string{"mod/base.mod", "mod/sub.mod"}) {
+		t.Errorf("unexpected enabled ids: %v", p.EnabledIds())
+	}
+	if !reflect.DeepEqual(p.DisplayOrder(), []string{"h2", "h1"}) {
+		t.Errorf("unexpected display order: %v", p.DisplayOrder())
+	}
+}
+
+func TestLoadPlayset_MissingRegistry(t *testing.T) {
+	if _, err := LoadPlayset(t.TempDir()); err == nil {
+		t.Error("expected error for missing registry")
+	}
+}
+
+func TestPlayset_ProposedAndCurrentOrder(t *testing.T) {
+	p, _ := LoadPlayset(writePlayset(t))
+	proposed := proposedOrder(t, p)
+	if got := GetModHashKeys(proposed); !reflect.DeepEqual(got, []string{"h1", "h2"}) {
+		t.Errorf("expected dependency first, got %v", got)
+	}
+	if got := GetModHashKeys(p.CurrentOrder(proposed)); !reflect.DeepEqual(got, []string{"h2", "h1"}) {
+		t.Errorf("expected game_data order, got %v", got)
+	}
+}
+
+func TestPlayset_Write(t *testing.T) {
+	dir := writePlayset(t)
+	p, _ := LoadPlayset(dir)
+	proposed := proposedOrder(t, p)
+	p.Write(proposed, []string{"mod/sub.mod"}, BakExt)
+	if !fileExists(filepath.Join(dir, DlcLoadFile+BakExt)) || !fileExists(filepath.Join(dir, GameDataFile+BakExt)) {
+		t.Error("expected backups of both files")
+	}
+	reloaded, _ := LoadPlayset(dir)
+	if !reflect.DeepEqual(reloaded.EnabledIds(), []string{"mod/sub.mod"}) {
+		t.Errorf("unexpected enabled ids after write: %v", reloaded.EnabledIds())
+	}
+	if !reflect.DeepEqual(reloaded.DisplayOrder(), []string{"h1", "h2"}) {
+		t.Errorf("unexpected display order after write: %v", reloaded.DisplayOrder())
+	}
+}
+
+func TestPlayset_EnabledOrder(t *testing.T) {
+	p, _ := LoadPlayset(writePlayset(t))
+	modList, _ := p.DescribedMods(context.Background())
+	order := p.EnabledOrder(modList)
+	if got := GetModHashKeys(order); !reflect.DeepEqual(got, []string{"h2", "h1"}) {
+		t.Errorf("expected reversed enabled_mods, got %v", got)
+	}
+	if v := CheckOrder(order, p.Registry, p.EnabledIds(), p.Rules); len(v) != 1 || v[0].Kind != ViolationDependency {
+		t.Errorf("expected Sub above Base to violate its dependency, got %v", v)
+	}
+}
+
+// writeOrderPlayset creates a settings directory with Base, Sub (depending on Base), Alpha and
+// Zulu registered as h1 to h4, and the given dlc_load.json and game_data.json contents.
+func writeOrderPlayset(t *testing.T, dlcLoad, gameData string) string {
+	t.Helper()
+	dir := t.TempDir()
+	descriptors := map[string]string{
+		"base":  "name=\"Base\"\n",
+		"sub":   "name=\"Sub\"\ndependencies={\n\t\"Base\"\n}\n",
+		"alpha": "name=\"Alpha\"\n",
+		"zulu":  "name=\"Zulu\"\n",
+	}
+	for name, desc := range descriptors {
+		os.Mkdir(filepath.Join(dir, name), 0755)
+		os.WriteFile(filepath.Join(dir, name, "descriptor.mod"), []byte(desc), 0644)
+	}
+	registry := `{
+		"h1": {"displayName": "Base", "gameRegistryId": "mod/base.mod", "dirPath": "` + filepath.ToSlash(filepath.Join(dir, "base")) + `"},
+		"h2": {"displayName": "Sub", "gameRegistryId": "mod/sub.mod", "dirPath": "` + filepath.ToSlash(filepath.Join(dir, "sub")) + `"},
+		"h3": {"displayName": "Alpha", "gameRegistryId": "mod/alpha.mod", "dirPath": "` + filepath.ToSlash(filepath.Join(dir, "alpha")) + `"},
+		"h4": {"displayName": "Zulu", "gameRegistryId": "mod/zulu.mod", "dirPath": "` + filepath.ToSlash(filepath.Join(dir, "zulu")) + `"}
+	}`
+	os.WriteFile(filepath.Join(dir, ModsRegistryFile), []byte(registry), 0644)
+	os.WriteFile(filepath.Join(dir, DlcLoadFile), []byte(dlcLoad), 0644)
+	os.WriteFile(filepath.Join(dir, GameDataFile), []byte(gameData), 0644)
+	return dir
+}
+
+func TestPlayset_SolveMinimal(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/base.mod", "mod/zulu.mod", "mod/sub.mod", "mod/alpha.mod"]}`,
+		`{"modsOrder": ["h3", "h2", "h4", "h1"]}`)
+	p, _ := LoadPlayset(dir)
+	p.Minimal = true
+	solution, _ := p.Solve(context.Background(), p.EnabledIds())
+	if got := GetModHashKeys(solution.Order); !reflect.DeepEqual(got, []string{"h3", "h4", "h1", "h2"}) {
+		t.Errorf("expected only Sub to move behind Base, got %v", got)
+	}
+	if moved := MovedCount(p.BaselineOrder(solution.Order), solution.Order); moved != 1 {
+		t.Errorf("expected 1 mod moved, got %d", moved)
+	}
+}
+
+func TestPlayset_SolveCancelled(t *testing.T) {
+	p, _ := LoadPlayset(writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/base.mod", "mod/sub.mod"]}`,
+		`{"modsOrder": ["h1", "h2", "h3", "h4"]}`))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Solve(ctx, p.EnabledIds()); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := p.DescribedMods(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPlayset_SolveEnabledOnly(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"enabled_mods": ["mod/alpha.mod", "mod/sub.mod", "mod/zulu.mod"]}`,
+		`{"modsOrder": ["h3", "h1", "h4", "h2"]}`)
+	p, _ := LoadPlayset(dir)
+	p.EnabledOnly = true
+	solution, _ := p.Solve(context.Background(), p.EnabledIds())
+	// Zulu, Sub, Alpha sorted reverse alphabetically, then the disabled Base
+	if got := GetModHashKeys(solution.Order); !reflect.DeepEqual(got, []string{"h4", "h2", "h3", "h1"}) {
+		t.Errorf("expected disabled mods last, got %v", got)
+	}
+	if len(solution.Broken) != 1 || solution.Broken[0].Kind != ViolationDisabledDependency || solution.Broken[0].Mod.Name != "Sub" {
+		t.Errorf("expected the disabled dependency of Sub, got %v", solution.Broken)
+	}
+
+	p.KeepDisabledPositions = true
+	solution, _ = p.Solve(context.Background(), p.EnabledIds())
+	if got := GetModHashKeys(solution.Order); !reflect.DeepEqual(got, []string{"h4", "h1", "h2", "h3"}) {
+		t.Errorf("expected Base to keep position 1, got %v", got)
+	}
+}
+
+func TestCheckDisabledDependencies_IgnoresDisabledMods(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"h1": {"displayName": "Base", "gameRegistryId": "mod/base.mod"},
+		"h2": {"displayName": "Sub", "gameRegistryId": "mod/sub.mod"},
+	}
+	modList := []*Mod{{HashKey: "h2", Name: "Sub", ModId: "mod/sub.mod", Dependencies: []string{"Base"}}}
+	if v := CheckDisabledDependencies(modList, data, nil); len(v) != 0 {
+		t.Errorf("expected no violations for a disabled mod, got %v", v)
+	}
+	if v := CheckDisabledDependencies(modList, data, []string{"mod/sub.mod", "mod/base.mod"}); len(v) != 0 {
+		t.Errorf("expected no violations with the dependency enabled, got %v", v)
+	}
+}
+
+func TestPlayset_WriteJournal(t *testing.T) {
+	dir := writePlayset(t)
+	p, _ := LoadPlayset(dir)
+	p.Command = "tui"
+	p.Write(proposedOrder(t, p), p.EnabledIds(), BakExt)
+	entries, err := LoadHistory(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one history entry, got %+v (%v)", entries, err)
+	}
+	if entries[0].Command != "tui" || len(entries[0].Files) != 2 {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+	if _, err := Undo(dir, 0, false); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if reloaded, _ := LoadPlayset(dir); !reflect.DeepEqual(reloaded.DisplayOrder(), []string{"h2", "h1"}) {
+		t.Errorf("expected the original modsOrder, got %v", reloaded.DisplayOrder())
+	}
+}
@@ -0,0 +1,247 @@
+package mods
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Ways a dependency entry can be matched to a registry entry, tried in this order.
+const (
+	MatchName       = "name"
+	MatchId         = "id"
+	MatchNormalized = "normalized"
+)
+
+// DependencyMatch is the result of resolving one dependency entry against the registry.
+type DependencyMatch struct {
+	Dependency string
+	HashKey    string
+	Method     string
+	Candidates []string
+}
+
+// Ambiguous reports whether more than one registry entry matched.
+func (m DependencyMatch) Ambiguous() bool {
+	return len(m.Candidates) > 1
+}
+
+// Prefer returns the first candidate that is enabled in idList, falling back to HashKey,
+// so a dependency on a mod installed twice points at the copy that is actually loaded.
+func (m DependencyMatch) Prefer(data map[string]map[string]interface{}, idList []string) string {
+	if len(m.Candidates) < 2 {
+		return m.HashKey
+	}
+	for _, h := range m.Candidates {
+		if id, _ := data[h]["gameRegistryId"].(string); id != "" && contains(idList, id) {
+			return h
+		}
+	}
+	return m.HashKey
+}
+
+// normalizeName lowercases a mod name and drops everything but ASCII letters and digits,
+// so "Chris’ Covert Operations" and "chris covert operations" compare equal.
+func normalizeName(s string) string {
+	var b strings.Builder
+	for _, r := range toASCII(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// ResolveDependency matches a dependency entry by exact display name, then by Steam ID or
+// .mod path, then by case- and punctuation-insensitive name. When several entries match at
+// the first successful stage the lowest HashKey is chosen and all candidates are returned.
+// Resolving the dependencies of many mods should share one DependencyIndex instead.
+func ResolveDependency(data map[string]map[string]interface{}, dep string) (DependencyMatch, bool) {
+	return NewDependencyIndex(data).Resolve(dep)
+}
+
+// DependencyIndex resolves dependency entries with the stages ResolveDependency describes,
+// indexing the registry once so resolving the dependencies of every mod stays linear.
+// It is not safe for concurrent use.
+type DependencyIndex struct {
+	data         map[string]map[string]interface{}
+	byName       map[string][]string
+	byId         map[string][]string
+	byNormalized map[string][]string
+}
+
+// NewDependencyIndex indexes the registry entries by display name and by Steam ID and .mod
+// path. The normalized names are only indexed once a dependency needs them.
+func NewDependencyIndex(data map[string]map[string]interface{}) *DependencyIndex {
+	ix := &DependencyIndex{
+		data:   data,
+		byName: make(map[string][]string, len(data)),
+		byId:   make(map[string][]string, len(data)),
+	}
+	for h, d := range data {
+		name, _ := d["displayName"].(string)
+		ix.byName[name] = append(ix.byName[name], h)
+		var ids []string
+		if id := registrySteamId(d); id != "" {
+			ids = append(ids, id)
+		}
+		if registryId, _ := d["gameRegistryId"].(string); registryId != "" {
+			ids = append(ids, registryId)
+			if strings.HasPrefix(registryId, "mod/ugc_") && strings.HasSuffix(registryId, ".mod") {
+				ids = append(ids, strings.TrimSuffix(strings.TrimPrefix(registryId, "mod/ugc_"), ".mod"))
+			}
+		}
+		for _, id := range ids {
+			if !contains(ix.byId[id], h) {
+				ix.byId[id] = append(ix.byId[id], h)
+			}
+		}
+	}
+	sortValues(ix.byName)
+	sortValues(ix.byId)
+	return ix
+}
+
+// normalized returns the entries by normalized name, indexing them on first use.
+func (ix *DependencyIndex) normalized() map[string][]string {
+	if ix.byNormalized == nil {
+		ix.byNormalized = make(map[string][]string, len(ix.data))
+		for h, d := range ix.data {
+			name, _ := d["displayName"].(string)
+			if n := normalizeName(name); n != "" {
+				ix.byNormalized[n] = append(ix.byNormalized[n], h)
+			}
+		}
+		sortValues(ix.byNormalized)
+	}
+	return ix.byNormalized
+}
+
+func sortValues(m map[string][]string) {
+	for _, hashes := range m {
+		if len(hashes) > 1 {
+			sort.Strings(hashes)
+		}
+	}
+}
+
+// Resolve matches dep like ResolveDependency does. The returned candidates are a copy.
+func (ix *DependencyIndex) Resolve(dep string) (DependencyMatch, bool) {
+	stages := []struct {
+		method     string
+		candidates func() []string
+	}{
+		{MatchName, func() []string { return ix.byName[dep] }},
+		{MatchId, func() []string { return ix.byId[strings.TrimSpace(dep)] }},
+		{MatchNormalized, func() []string { return ix.normalized()[normalizeName(dep)] }},
+	}
+	for _, stage := range stages {
+		if found := stage.candidates(); len(found) > 0 {
+			candidates := append([]string{}, found...)
+			return DependencyMatch{Dependency: dep, HashKey: candidates[0], Method: stage.method, Candidates: candidates}, true
+		}
+	}
+	return DependencyMatch{Dependency: dep}, false
+}
+
+// FindDuplicates groups registry entries that are installs of the same mod: entries sharing
+// a Steam ID, or sharing a normalized display name. Groups and their members are sorted.
+func FindDuplicates(data map[string]map[string]interface{}) [][]string {
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(h string) string {
+		if parent[h] != h {
+			parent[h] = find(parent[h])
+		}
+		return parent[h]
+	}
+	union := func(a, b string) {
+		ra, rb := find(a), find(b)
+		if ra < rb {
+			parent[rb] = ra
+		} else if rb < ra {
+			parent[ra] = rb
+		}
+	}
+	bySteamId := map[string]string{}
+	byName := map[string]string{}
+	for h := range data {
+		parent[h] = h
+	}
+	for h, d := range data {
+		if id := registrySteamId(d); id != "" {
+			if other, ok := bySteamId[id]; ok {
+				union(h, other)
+			} else {
+				bySteamId[id] = h
+			}
+		}
+		name, _ := d["displayName"].(string)
+		if n := normalizeName(name); n != "" {
+			if other, ok := byName[n]; ok {
+				union(h, other)
+			} else {
+				byName[n] = h
+			}
+		}
+	}
+	groups := map[string][]string{}
+	for h := range data {
+		root := find(h)
+		groups[root] = append(groups[root], h)
+	}
+	var result [][]string
+	for _, g := range groups {
+		if len(g) > 1 {
+			sort.Strings(g)
+			result = append(result, g)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+// KeepDuplicate returns idList with the other copies in keep's duplicate group removed and
+// keep enabled in place of the first removed copy. It returns false when keep has no duplicates.
+func KeepDuplicate(data map[string]map[string]interface{}, idList []string, keep string) ([]string, bool) {
+	var group []string
+	for _, g := range FindDuplicates(data) {
+		if contains(g, keep) {
+			group = g
+		}
+	}
+	if group == nil {
+		return idList, false
+	}
+	keepId, _ := data[keep]["gameRegistryId"].(string)
+	drop := map[string]bool{}
+	for _, h := range group {
+		if id, _ := data[h]["gameRegistryId"].(string); h != keep && id != keepId {
+			drop[id] = true
+		}
+	}
+	result := []string{}
+	placed := false
+	for _, id := range idList {
+		switch {
+		case id == keepId:
+			if !placed {
+				result = append(result, id)
+				placed = true
+			}
+		case drop[id]:
+			if !placed {
+				result = append(result, keepId)
+				placed = true
+			}
+		default:
+			result = append(result, id)
+		}
+	}
+	if !placed {
+		result = append(result, keepId)
+	}
+	return result, true
+}
@@ -0,0 +1,96 @@
+package mods
+
+import (
+	"reflect"
+	"testing"
+)
+
+func resolveRegistry() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"a1": {"displayName": "UI Overhaul Dynamic", "gameRegistryId": "mod/ugc_1623423360.mod", "steamId": "1623423360", "source": "steam"},
+		"a2": {"displayName": "UI Overhaul Dynamic", "gameRegistryId": "mod/uiod_local.mod", "source": "local"},
+		"b1": {"displayName": "Chris’ Covert Operations", "gameRegistryId": "mod/ugc_42.mod", "steamId": 42.0},
+		"c1": {"displayName": "Planetary Diversity", "gameRegistryId": "mod/pd.mod"},
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	if normalizeName("Chris’ Covert Operations") != normalizeName("chris covert-operations") {
+		t.Error("expected punctuation and case to be ignored")
+	}
+	if normalizeName("!!!") != "" {
+		t.Error("expected empty result for punctuation only")
+	}
+}
+
+func TestResolveDependency(t *testing.T) {
+	data := resolveRegistry()
+	data["d1"] = map[string]interface{}{"displayName": "!!!", "gameRegistryId": "mod/bang.mod"}
+	// One index serves every case, so the lazily built normalized names are shared as in BuildConstraints
+	index := NewDependencyIndex(data)
+	cases := []struct {
+		dep, hash, method string
+		candidates        []string
+	}{
+		{"Planetary Diversity", "c1", MatchName, []string{"c1"}},
+		{"42", "b1", MatchId, []string{"b1"}},
+		{" 42 ", "b1", MatchId, []string{"b1"}},
+		{"1623423360", "a1", MatchId, []string{"a1"}},
+		{"mod/ugc_1623423360.mod", "a1", MatchId, []string{"a1"}},
+		{"mod/uiod_local.mod", "a2", MatchId, []string{"a2"}},
+		{"chris' covert operations", "b1", MatchNormalized, []string{"b1"}},
+		{"UI Overhaul Dynamic", "a1", MatchName, []string{"a1", "a2"}},
+		{"ui overhaul dynamic", "a1", MatchNormalized, []string{"a1", "a2"}},
+		{"!!!", "d1", MatchName, []string{"d1"}},
+		{"???", "", "", nil},
+		{"Nope", "", "", nil},
+	}
+	for _, c := range cases {
+		want := DependencyMatch{Dependency: c.dep, HashKey: c.hash, Method: c.method, Candidates: c.candidates}
+		m, found := ResolveDependency(data, c.dep)
+		if found != (c.hash != "") || !reflect.DeepEqual(m, want) {
+			t.Errorf("ResolveDependency(%q) = %+v, %v, want %+v", c.dep, m, found, want)
+		}
+		im, ifound := index.Resolve(c.dep)
+		if ifound != found || !reflect.DeepEqual(im, m) {
+			t.Errorf("DependencyIndex.Resolve(%q) = %+v, %v, ResolveDependency gave %+v, %v", c.dep, im, ifound, m, found)
+		}
+		if len(im.Candidates) > 0 {
+			// Callers own the candidates, changing them must not leak into later lookups
+			im.Candidates[0] = "x"
+		}
+	}
+}
+
+func TestDependencyMatch_Prefer(t *testing.T) {
+	data := resolveRegistry()
+	m, _ := ResolveDependency(data, "UI Overhaul Dynamic")
+	if got := m.Prefer(data, []string{"mod/uiod_local.mod"}); got != "a2" {
+		t.Errorf("expected enabled copy a2, got %s", got)
+	}
+	if got := m.Prefer(data, nil); got != "a1" {
+		t.Errorf("expected fallback a1, got %s", got)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	data := resolveRegistry()
+	data["b2"] = map[string]interface{}{"displayName": "Covert Ops (local)", "gameRegistryId": "mod/cov.mod", "steamId": "42"}
+	got := FindDuplicates(data)
+	want := [][]string{{"a1", "a2"}, {"b1", "b2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDuplicates = %v, want %v", got, want)
+	}
+}
+
+func TestKeepDuplicate(t *testing.T) {
+	data := resolveRegistry()
+	idList := []string{"mod/pd.mod", "mod/ugc_1623423360.mod", "mod/ugc_42.mod"}
+	got, ok := KeepDuplicate(data, idList, "a2")
+	if !ok || !reflect.DeepEqual(got, []string{"mod/pd.mod", "mod/uiod_local.mod", "mod/ugc_42.mod"}) {
+		t.Errorf("unexpected ids %v, %v", got, ok)
+	}
+	if _, ok := KeepDuplicate(data, idList, "c1"); ok {
+		t.Error("expected no duplicate group for c1")
+	}
+}
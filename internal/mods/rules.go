@@ -0,0 +1,45 @@
+package mods
+
+// SortRules holds the game specific rules of the tag and special order passes.
+// Mods tagged with EarlyTags are moved behind the rest of the list first, mods tagged with
+// LateTags last; PatchTag mods join the late group unless already in it. SpecialNames lists
+// mods that must load in this relative order, matched by substring.
+type SortRules struct {
+	EarlyTags    []string
+	LateTags     []string
+	PatchTag     string
+	SpecialNames []string
+}
+
+// StellarisRules are the tag tiers and special order rules of Stellaris.
+var StellarisRules = SortRules{
+	EarlyTags:    []string{"OST", "Music", "Sound", "Graphics"},
+	LateTags:     []string{"AI", "Utilities", "Fixes"},
+	PatchTag:     "Patch",
+	SpecialNames: []string{"UI Overhaul Dynamic", "Dark UI", "Dark U1"},
+}
+
+// Tag tiers shared by the games without Stellaris specific rules.
+var commonRules = SortRules{
+	EarlyTags: []string{"Music", "Sound", "Graphics"},
+	LateTags:  []string{"Utilities", "Fixes"},
+	PatchTag:  "Patch",
+}
+
+// GameRules lists the sort rules by game profile key.
+var GameRules = map[string]SortRules{
+	"stellaris": StellarisRules,
+	"eu4":       commonRules,
+	"hoi4":      commonRules,
+	"ck3":       commonRules,
+	"vic3":      commonRules,
+}
+
+// RulesForGame returns the sort rules of the game profile key, or the common tag tiers for a
+// game without rules of its own.
+func RulesForGame(key string) SortRules {
+	if rules, ok := GameRules[key]; ok {
+		return rules
+	}
+	return commonRules
+}
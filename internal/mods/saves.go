@@ -0,0 +1,224 @@
+package mods
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"stellaris-mod-sorter-go/internal/paradox"
+)
+
+// Save games inside the settings directory, and the game files describing the installed
+// version and DLCs inside the game folder.
+const (
+	SaveGamesDir         = "save games"
+	SaveExt              = ".sav"
+	LauncherSettingsFile = "launcher-settings.json"
+	DlcDir               = "dlc"
+)
+
+// Kinds of save game incompatibilities.
+const (
+	SaveIssueVersion     = "version"
+	SaveIssueDisabledDlc = "disabled-dlc"
+	SaveIssueMissingDlc  = "missing-dlc"
+)
+
+// SaveMeta holds the meta entry of a .sav archive.
+type SaveMeta struct {
+	Path         string
+	Name         string
+	Version      string
+	Date         string
+	RequiredDlcs []string
+}
+
+// SaveIssue is one reason a save may not load with the current setup.
+type SaveIssue struct {
+	Kind    string
+	Message string
+}
+
+// GameInstall describes the game folder: its version and the display name of every DLC by
+// the path disabled_dlcs uses for it, such as dlc/dlc004_leviathan/dlc004.dlc.
+type GameInstall struct {
+	Version string
+	Dlcs    map[string]string
+}
+
+var versionNumber = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+// ParseSaveMeta parses the content of the meta entry of a save.
+func ParseSaveMeta(content string) (SaveMeta, error) {
+	root, err := paradox.ParseString(content)
+	if err != nil {
+		return SaveMeta{}, err
+	}
+	return SaveMeta{
+		Name:         root.String("name"),
+		Version:      root.String("version"),
+		Date:         root.String("date"),
+		RequiredDlcs: root.Strings("required_dlcs"),
+	}, nil
+}
+
+// ReadSaveMeta reads the meta entry of the .sav zip archive at path.
+func ReadSaveMeta(path string) (SaveMeta, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return SaveMeta{}, fmt.Errorf("%s: %w", path, err)
+	}
+	defer archive.Close()
+	for _, f := range archive.File {
+		if f.Name != "meta" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return SaveMeta{}, fmt.Errorf("%s: %w", path, err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return SaveMeta{}, fmt.Errorf("%s: %w", path, err)
+		}
+		meta, err := ParseSaveMeta(string(content))
+		if err != nil {
+			return SaveMeta{}, fmt.Errorf("%s meta: %w", path, err)
+		}
+		meta.Path = path
+		return meta, nil
+	}
+	return SaveMeta{}, fmt.Errorf("%s: no meta entry", path)
+}
+
+// ListSaves returns the .sav files below the save games folder of settingsPath, most recently
+// written first. A missing folder yields no saves.
+func ListSaves(settingsPath string) ([]string, error) {
+	dir := filepath.Join(settingsPath, SaveGamesDir)
+	modTimes := map[string]int64{}
+	var saves []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), SaveExt) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			modTimes[path] = info.ModTime().UnixNano()
+		}
+		saves = append(saves, path)
+		return nil
+	})
+	sort.SliceStable(saves, func(i, j int) bool {
+		if modTimes[saves[i]] != modTimes[saves[j]] {
+			return modTimes[saves[i]] > modTimes[saves[j]]
+		}
+		return saves[i] < saves[j]
+	})
+	return saves, err
+}
+
+// ReadGameInstall reads the version from launcher-settings.json and the DLC names from the
+// .dlc files of the game folder gameDir.
+func ReadGameInstall(gameDir string) (GameInstall, error) {
+	install := GameInstall{Dlcs: map[string]string{}}
+	content, err := os.ReadFile(filepath.Join(gameDir, LauncherSettingsFile))
+	if err != nil {
+		return install, err
+	}
+	var settings struct {
+		Version    string `json:"version"`
+		RawVersion string `json:"rawVersion"`
+	}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return install, fmt.Errorf("%s: %w", LauncherSettingsFile, err)
+	}
+	install.Version = settings.RawVersion
+	if install.Version == "" {
+		install.Version = settings.Version
+	}
+	files, _ := filepath.Glob(filepath.Join(gameDir, DlcDir, "*", "*.dlc"))
+	for _, file := range files {
+		desc, err := ReadDescriptor(file)
+		if err != nil || desc.Name == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(gameDir, file); err == nil {
+			install.Dlcs[filepath.ToSlash(rel)] = desc.Name
+		}
+	}
+	return install, nil
+}
+
+// CheckSave compares a save with the game version and the DLCs disabled in dlc_load.json.
+// A save made with another major or minor version is flagged; patch versions are ignored.
+// The DLC checks are skipped when install lists no DLCs, because disabled_dlcs holds paths
+// and saves hold names.
+func CheckSave(meta SaveMeta, install GameInstall, disabledDlcs []string) []SaveIssue {
+	var issues []SaveIssue
+	save, game := parseVersion(meta.Version), parseVersion(install.Version)
+	if save != nil && game != nil {
+		if cmp := compareMinor(save, game); cmp != 0 {
+			relation := "an older"
+			if cmp > 0 {
+				relation = "a newer"
+			}
+			issues = append(issues, SaveIssue{SaveIssueVersion,
+				fmt.Sprintf("saved with %s version %s, the game is %s", relation, meta.Version, install.Version)})
+		}
+	}
+	if len(install.Dlcs) == 0 {
+		return issues
+	}
+	disabled, installed := dlcNames(install, disabledDlcs)
+	for _, name := range meta.RequiredDlcs {
+		switch {
+		case disabled[name]:
+			issues = append(issues, SaveIssue{SaveIssueDisabledDlc, "requires " + name + ", which is disabled"})
+		case !installed[name]:
+			issues = append(issues, SaveIssue{SaveIssueMissingDlc, "requires " + name + ", which is not installed"})
+		}
+	}
+	return issues
+}
+
+// parseVersion returns the numbers of the first dotted version in s, such as 3.4.5 in
+// "Butler v3.4.5", or nil when there is none.
+func parseVersion(s string) []int {
+	match := versionNumber.FindString(s)
+	if match == "" {
+		return nil
+	}
+	var parts []int
+	for _, p := range strings.Split(match, ".") {
+		n, _ := strconv.Atoi(p)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// compareMinor compares the major and minor numbers of two versions.
+func compareMinor(a, b []int) int {
+	for i := 0; i < 2 && i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+package mods
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testSaveMeta = `version="Butler v3.4.5"
+version_control_revision=82374
+name="United Nations of Earth"
+date="2250.03.01"
+required_dlcs={
+	"Leviathans Story Pack"
+	"Utopia"
+}
+player_portrait="human"
+meta_planets=4
+`
+
+func writeSave(t *testing.T, path, meta string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{"meta": meta, "gamestate": "tick=0\n"} {
+		entry, _ := w.Create(name)
+		entry.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadSaveMeta(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autosave.sav")
+	writeSave(t, path, testSaveMeta)
+	meta, err := ReadSaveMeta(path)
+	if err != nil {
+		t.Fatalf("ReadSaveMeta failed: %v", err)
+	}
+	want := SaveMeta{Path: path, Name: "United Nations of Earth", Version: "Butler v3.4.5", Date: "2250.03.01",
+		RequiredDlcs: []string{"Leviathans Story Pack", "Utopia"}}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("got %+v, want %+v", meta, want)
+	}
+}
+
+func TestListSaves_NewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	if saves, err := ListSaves(dir); err != nil || len(saves) != 0 {
+		t.Fatalf("expected no saves without a save games folder, got %v, %v", saves, err)
+	}
+	older := filepath.Join(dir, SaveGamesDir, "empire", "2200.01.01.sav")
+	newer := filepath.Join(dir, SaveGamesDir, "empire", "autosave.sav")
+	writeSave(t, older, testSaveMeta)
+	writeSave(t, newer, testSaveMeta)
+	os.WriteFile(filepath.Join(dir, SaveGamesDir, "empire", "notes.txt"), nil, 0644)
+	os.Chtimes(older, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+	saves, err := ListSaves(dir)
+	if err != nil || !reflect.DeepEqual(saves, []string{newer, older}) {
+		t.Errorf("expected %v, got %v, %v", []string{newer, older}, saves, err)
+	}
+}
+
+func TestReadGameInstall(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, LauncherSettingsFile), []byte(`{"version": "v3.4.5", "rawVersion": "3.4.5"}`), 0644)
+	os.MkdirAll(filepath.Join(dir, DlcDir, "dlc004_leviathan"), 0755)
+	os.WriteFile(filepath.Join(dir, DlcDir, "dlc004_leviathan", "dlc004.dlc"), []byte("name = \"Leviathans Story Pack\"\n"), 0644)
+	install, err := ReadGameInstall(dir)
+	if err != nil {
+		t.Fatalf("ReadGameInstall failed: %v", err)
+	}
+	want := GameInstall{Version: "3.4.5", Dlcs: map[string]string{"dlc/dlc004_leviathan/dlc004.dlc": "Leviathans Story Pack"}}
+	if !reflect.DeepEqual(install, want) {
+		t.Errorf("got %+v, want %+v", install, want)
+	}
+}
+
+func TestCheckSave(t *testing.T) {
+	meta, _ := ParseSaveMeta(testSaveMeta)
+	install := GameInstall{Version: "3.4.2", Dlcs: map[string]string{
+		"dlc/dlc004_leviathan/dlc004.dlc": "Leviathans Story Pack",
+		"dlc/dlc017_utopia/dlc017.dlc":    "Utopia",
+	}}
+	if issues := CheckSave(meta, install, nil); len(issues) != 0 {
+		t.Errorf("expected a patch difference to be compatible, got %v", issues)
+	}
+
+	install.Version = "3.5.0"
+	issues := CheckSave(meta, install, []string{"dlc/dlc017_utopia/dlc017.dlc"})
+	var kinds []string
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	if !reflect.DeepEqual(kinds, []string{SaveIssueVersion, SaveIssueDisabledDlc}) {
+		t.Errorf("expected version and disabled DLC issues, got %v", issues)
+	}
+
+	delete(install.Dlcs, "dlc/dlc004_leviathan/dlc004.dlc")
+	install.Version = ""
+	issues = CheckSave(meta, install, nil)
+	if len(issues) != 1 || issues[0].Kind != SaveIssueMissingDlc {
+		t.Errorf("expected a missing DLC issue, got %v", issues)
+	}
+
+	if issues := CheckSave(meta, GameInstall{}, []string{"dlc/dlc017_utopia/dlc017.dlc"}); len(issues) != 0 {
+		t.Errorf("expected the DLC checks to be skipped without an install, got %v", issues)
+	}
+}
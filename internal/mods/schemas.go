@@ -0,0 +1,81 @@
+package mods
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:embed schemas/*.schema.json
+var schemaFiles embed.FS
+
+// LauncherFiles lists the launcher files validated before a sort, in validation order.
+var LauncherFiles = []string{ModsRegistryFile, DlcLoadFile, GameDataFile}
+
+// launcherSchemas maps each launcher file to its embedded schema.
+var launcherSchemas = map[string]string{
+	ModsRegistryFile: "schemas/mods_registry.schema.json",
+	DlcLoadFile:      "schemas/dlc_load.schema.json",
+	GameDataFile:     "schemas/game_data.schema.json",
+}
+
+// LauncherSchema compiles the embedded schema of a launcher file, named like DlcLoadFile.
+func LauncherSchema(file string) (*jsonschema.Schema, error) {
+	name, ok := launcherSchemas[file]
+	if !ok {
+		return nil, fmt.Errorf("no schema for %s", file)
+	}
+	f, err := schemaFiles.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	doc, err := jsonschema.UnmarshalJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	url := "https://stellaris-mod-sorter.invalid/" + name
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
+
+// ValidateLauncherFile validates the file at path against the embedded schema for its name.
+func ValidateLauncherFile(path string) error {
+	schema, err := LauncherSchema(filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	doc, err := jsonschema.UnmarshalJSON(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// ValidateLauncherFiles validates every launcher file of settingsPath and joins the errors of
+// the invalid ones. Missing files are skipped; LoadPlayset reports a missing registry.
+func ValidateLauncherFiles(settingsPath string) error {
+	var errs []error
+	for _, file := range LauncherFiles {
+		err := ValidateLauncherFile(filepath.Join(settingsPath, file))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLauncherSchema_AllFiles(t *testing.T) {
+	for _, file := range LauncherFiles {
+		if _, err := LauncherSchema(file); err != nil {
+			t.Errorf("schema of %s does not compile: %v", file, err)
+		}
+	}
+	if _, err := LauncherSchema("settings.txt"); err == nil {
+		t.Error("expected error for a file without schema")
+	}
+}
+
+func TestValidateLauncherFile_Examples(t *testing.T) {
+	// The examples at the repository root, copied under their launcher file names
+	dir := t.TempDir()
+	for src, dst := range map[string]string{"example_registry.json": ModsRegistryFile, "dlc_load_example.json": DlcLoadFile} {
+		content, err := os.ReadFile(filepath.Join("..", "..", src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(filepath.Join(dir, dst), content, 0644)
+	}
+	if err := ValidateLauncherFiles(dir); err != nil {
+		t.Errorf("expected the examples to be valid: %v", err)
+	}
+}
+
+func TestValidateLauncherFiles_Invalid(t *testing.T) {
+	dir := writePlayset(t)
+	if err := ValidateLauncherFiles(dir); err != nil {
+		t.Fatalf("expected a valid playset: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, DlcLoadFile), []byte(`{"enabled_mods": "mod/base.mod"}`), 0644)
+	os.WriteFile(filepath.Join(dir, GameDataFile), []byte(`{"modsOrder": ["h1", "h1"]}`), 0644)
+	err := ValidateLauncherFiles(dir)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, file := range []string{DlcLoadFile, GameDataFile} {
+		if !strings.Contains(err.Error(), file) {
+			t.Errorf("expected an error for %s, got %v", file, err)
+		}
+	}
+	if err := ValidateLauncherFile(filepath.Join(t.TempDir(), DlcLoadFile)); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error for a missing file, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package mods
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SharePrefix starts every share code so a pasted code is recognized and future formats can
+// change the number.
+const SharePrefix = "SMS1-"
+
+// ErrInvalidShareCode is returned when a code cannot be decoded.
+var ErrInvalidShareCode = errors.New("invalid share code")
+
+// maxShareCodeSize caps the decompressed size of a share code. A few thousand mods fit in a
+// fraction of it, so anything larger is a malformed or malicious code.
+const maxShareCodeSize = 1 << 20
+
+// ShareMod names one shared mod by Steam ID, or by display name for local mods.
+type ShareMod struct {
+	SteamId string `json:"s,omitempty"`
+	Name    string `json:"n,omitempty"`
+}
+
+// ShareCode is a playset exchanged between players: the enabled mods in sorter order and the
+// disabled DLCs. The JSON keys are short to keep the encoded code compact.
+type ShareCode struct {
+	Mods         []ShareMod `json:"m"`
+	DisabledDlcs []string   `json:"d,omitempty"`
+}
+
+// NewShareCode shares loadOrder, the enabled mods in sorter order, and disabledDlcs.
+func NewShareCode(loadOrder []*Mod, disabledDlcs []string) ShareCode {
+	code := ShareCode{Mods: []ShareMod{}, DisabledDlcs: disabledDlcs}
+	for _, mod := range loadOrder {
+		if mod.SteamId != "" {
+			code.Mods = append(code.Mods, ShareMod{SteamId: mod.SteamId})
+		} else {
+			code.Mods = append(code.Mods, ShareMod{Name: mod.Name})
+		}
+	}
+	return code
+}
+
+// Encode compresses the code into a single line of URL-safe base64 after SharePrefix.
+func (c ShareCode) Encode() (string, error) {
+	content, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(content); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return SharePrefix + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeShareCode reverses Encode. Surrounding whitespace and line breaks added by chat
+// clients are ignored. Codes decompressing to more than maxShareCodeSize are rejected.
+func DecodeShareCode(s string) (ShareCode, error) {
+	s = strings.Join(strings.Fields(s), "")
+	if !strings.HasPrefix(s, SharePrefix) {
+		return ShareCode{}, fmt.Errorf("%w: missing %s prefix", ErrInvalidShareCode, SharePrefix)
+	}
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, SharePrefix))
+	if err != nil {
+		return ShareCode{}, fmt.Errorf("%w: %v", ErrInvalidShareCode, err)
+	}
+	content, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxShareCodeSize+1))
+	if err != nil {
+		return ShareCode{}, fmt.Errorf("%w: %v", ErrInvalidShareCode, err)
+	}
+	if len(content) > maxShareCodeSize {
+		return ShareCode{}, fmt.Errorf("%w: larger than %d bytes", ErrInvalidShareCode, maxShareCodeSize)
+	}
+	var code ShareCode
+	if err := json.Unmarshal(content, &code); err != nil {
+		return ShareCode{}, fmt.Errorf("%w: %v", ErrInvalidShareCode, err)
+	}
+	return code, nil
+}
+
+// Resolve finds the shared mods in modList, by Steam ID or else by exact and then normalized
+// display name. It returns the installed ones in shared order and the shared mods not installed.
+func (c ShareCode) Resolve(modList []*Mod) ([]*Mod, []ShareMod) {
+	var found []*Mod
+	var missing []ShareMod
+	used := map[*Mod]bool{}
+	for _, shared := range c.Mods {
+		mod := findShared(modList, shared, used)
+		if mod == nil {
+			missing = append(missing, shared)
+			continue
+		}
+		used[mod] = true
+		found = append(found, mod)
+	}
+	return found, missing
+}
+
+func findShared(modList []*Mod, shared ShareMod, used map[*Mod]bool) *Mod {
+	matches := []func(*Mod) bool{
+		func(m *Mod) bool { return shared.SteamId != "" && m.SteamId == shared.SteamId },
+		func(m *Mod) bool { return shared.Name != "" && m.Name == shared.Name },
+		func(m *Mod) bool { return shared.Name != "" && normalizeName(m.Name) == normalizeName(shared.Name) },
+	}
+	for _, match := range matches {
+		for _, mod := range modList {
+			if !used[mod] && match(mod) {
+				return mod
+			}
+		}
+	}
+	return nil
+}
+
+// String names a shared mod for reports.
+func (m ShareMod) String() string {
+	if m.SteamId != "" {
+		return "Steam workshop item " + m.SteamId
+	}
+	return m.Name
+}
+
+// ApplyShare writes the installed mods of found as the enabled mods in exactly the shared
+// order, followed in modsOrder by the other mods in their current order, and stores the
+// shared disabled_dlcs.
+func (p *Playset) ApplyShare(code ShareCode, found []*Mod, bakExt string) {
+	modList := p.CurrentOrder(GetModList(p.Registry))
+	order := append([]*Mod{}, found...)
+	shared := map[string]bool{}
+	var idList []string
+	for _, mod := range found {
+		shared[mod.HashKey] = true
+		idList = append(idList, mod.ModId)
+	}
+	for _, mod := range modList {
+		if !shared[mod.HashKey] {
+			order = append(order, mod)
+		}
+	}
+	disabled := make([]interface{}, len(code.DisabledDlcs))
+	for i, dlc := range code.DisabledDlcs {
+		disabled[i] = dlc
+	}
+	p.DlcLoad["disabled_dlcs"] = disabled
+	p.Write(order, idList, bakExt)
+}
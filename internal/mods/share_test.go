@@ -0,0 +1,87 @@
+package mods
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShareCode_RoundTrip(t *testing.T) {
+	loadOrder := []*Mod{
+		{Name: "UI Overhaul Dynamic", SteamId: "1623423360"},
+		{Name: "My Local Patch"},
+	}
+	code := NewShareCode(loadOrder, []string{"dlc/dlc004_leviathan/dlc004.dlc"})
+	encoded, err := code.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !strings.HasPrefix(encoded, SharePrefix) || strings.ContainsAny(encoded, " \n") {
+		t.Errorf("expected a single-line code with prefix, got %q", encoded)
+	}
+	// Chat clients may wrap long codes
+	decoded, err := DecodeShareCode("  " + encoded[:10] + "\n" + encoded[10:] + "\n")
+	if err != nil || !reflect.DeepEqual(decoded, code) {
+		t.Errorf("expected %+v, got %+v, %v", code, decoded, err)
+	}
+	if _, err := DecodeShareCode(SharePrefix + "not-a-code"); !errors.Is(err, ErrInvalidShareCode) {
+		t.Errorf("expected ErrInvalidShareCode, got %v", err)
+	}
+}
+
+func TestDecodeShareCode_TooLarge(t *testing.T) {
+	// A valid code whose mod names decompress to more than the cap
+	code := ShareCode{Mods: []ShareMod{{Name: strings.Repeat("a", maxShareCodeSize)}}}
+	encoded, err := code.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if len(encoded) > maxShareCodeSize/100 {
+		t.Fatalf("expected the code to compress well, got %d bytes", len(encoded))
+	}
+	if _, err := DecodeShareCode(encoded); !errors.Is(err, ErrInvalidShareCode) {
+		t.Errorf("expected ErrInvalidShareCode, got %v", err)
+	}
+}
+
+func TestShareCode_Resolve(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "h1", Name: "UI Overhaul Dynamic", SteamId: "1623423360"},
+		{HashKey: "h2", Name: "My Local Patch"},
+	}
+	code := ShareCode{Mods: []ShareMod{{Name: "my local patch"}, {SteamId: "999"}, {SteamId: "1623423360"}}}
+	found, missing := code.Resolve(modList)
+	if got := GetModHashKeys(found); !reflect.DeepEqual(got, []string{"h2", "h1"}) {
+		t.Errorf("expected shared order h2, h1, got %v", got)
+	}
+	if !reflect.DeepEqual(missing, []ShareMod{{SteamId: "999"}}) {
+		t.Errorf("expected item 999 missing, got %v", missing)
+	}
+}
+
+func TestPlayset_ApplyShare(t *testing.T) {
+	dir := writeOrderPlayset(t,
+		`{"disabled_dlcs": [], "enabled_mods": ["mod/alpha.mod"]}`,
+		`{"modsOrder": ["h3", "h4", "h2", "h1"]}`)
+	p, _ := LoadPlayset(dir)
+	code := ShareCode{Mods: []ShareMod{{Name: "Base"}, {Name: "Zulu"}, {Name: "Sub"}}, DisabledDlcs: []string{"dlc/dlc017_utopia/dlc017.dlc"}}
+	found, _ := code.Resolve(GetModList(p.Registry))
+	p.ApplyShare(code, found, BakExt)
+
+	reloaded, _ := LoadPlayset(dir)
+	if got := reloaded.DisplayOrder(); !reflect.DeepEqual(got, []string{"h1", "h4", "h2", "h3"}) {
+		t.Errorf("expected the shared mods first in shared order, got %v", got)
+	}
+	if got := reloaded.EnabledIds(); !reflect.DeepEqual(got, []string{"mod/sub.mod", "mod/zulu.mod", "mod/base.mod"}) {
+		t.Errorf("unexpected enabled_mods %v", got)
+	}
+	if got := reloaded.DisabledDlcs(); !reflect.DeepEqual(got, code.DisabledDlcs) {
+		t.Errorf("expected disabled_dlcs %v, got %v", code.DisabledDlcs, got)
+	}
+	// Sharing the applied playset gives back the same code
+	again := NewShareCode(reloaded.EnabledOrder(GetModList(reloaded.Registry)), reloaded.DisabledDlcs())
+	if !reflect.DeepEqual(again, code) {
+		t.Errorf("expected %+v, got %+v", code, again)
+	}
+}
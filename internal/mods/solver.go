@@ -0,0 +1,454 @@
+package mods
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+// Soft constraint weights. A heavier constraint is only broken to satisfy a hard one or when
+// keeping it would break more constraints of the same weight; the weights are spaced so that
+// a kind outweighs any realistic number of constraints of the kinds below it.
+const (
+	WeightSpecialOrder = 1000000
+	WeightTagTier      = 1000
+	WeightBaseOrder    = 1
+)
+
+// Constraint requires the mod at Before to load ahead of the mod at After, both indexes into
+// the mod list of a ConstraintGraph. Hard constraints have a Weight of 0.
+type Constraint struct {
+	Before int
+	After  int
+	Kind   string
+	Weight int
+	Cause  string
+}
+
+// Hard reports whether the constraint must never be broken.
+func (c Constraint) Hard() bool {
+	return c.Weight == 0
+}
+
+// ConstraintGraph collects the ordering constraints between the mods of a list.
+// Dependencies and pins are hard, tag tiers, special order rules and the base order soft.
+// Constraints lists the dependency and special order constraints; the tag tier and base order
+// constraints hold between most pairs of mods, so they are kept implicitly as the tier and the
+// position of each mod.
+type ConstraintGraph struct {
+	Mods        []*Mod
+	Constraints []Constraint
+	Pins        map[int]int
+	tiers       []int
+	ranks       []int
+	pinned      []int
+	hardBefore  [][]int
+}
+
+// Solution is an order produced by Solve together with the constraints it breaks.
+type Solution struct {
+	Order  []*Mod
+	Broken []Violation
+}
+
+// BuildConstraints collects the constraints of modList. The position of a mod in modList is
+// its base order, which every other constraint takes precedence over.
+func BuildConstraints(modList []*Mod, data map[string]map[string]interface{}, idList []string, pins map[string]int, rules SortRules) *ConstraintGraph {
+	n := len(modList)
+	g := &ConstraintGraph{
+		Mods:       modList,
+		Pins:       map[int]int{},
+		tiers:      make([]int, n),
+		ranks:      make([]int, n),
+		pinned:     make([]int, n),
+		hardBefore: make([][]int, n),
+	}
+	set := NewModSet(modList)
+	for i, mod := range modList {
+		g.tiers[i] = rules.TagTier(mod)
+		g.ranks[i] = rules.specialRank(mod)
+		g.pinned[i] = -1
+		if want, ok := pins[mod.HashKey]; ok {
+			g.Pins[i] = clamp(want, n)
+			g.pinned[i] = g.Pins[i]
+		}
+	}
+
+	deps := NewDependencyIndex(data)
+	for i, mod := range modList {
+		for _, dep := range mod.Dependencies {
+			match, found := deps.Resolve(dep)
+			if !found {
+				continue
+			}
+			if j, ok := set.Index(match.Prefer(data, idList)); ok && j != i {
+				g.add(Constraint{Before: j, After: i, Kind: ViolationDependency, Cause: "depends on " + dep})
+			}
+		}
+	}
+	for i := range modList {
+		if g.ranks[i] < 0 {
+			continue
+		}
+		for j := range modList {
+			if g.ranks[j] >= 0 && g.ranks[i] < g.ranks[j] {
+				g.add(Constraint{Before: i, After: j, Kind: ViolationSpecialOrder, Weight: WeightSpecialOrder,
+					Cause: modList[i].Name + " must load before it"})
+			}
+		}
+	}
+	return g
+}
+
+func (g *ConstraintGraph) add(c Constraint) {
+	g.Constraints = append(g.Constraints, c)
+	if c.Hard() {
+		g.hardBefore[c.After] = append(g.hardBefore[c.After], c.Before)
+	}
+}
+
+// Solve orders the mods greedily: each position takes the due pinned mod if there is one,
+// otherwise the mod whose hard constraints are met that breaks the least soft weight against
+// the mods still unplaced. Hard constraints are only broken when they form a cycle.
+//
+// The weight a mod breaks is the number of unplaced mods ahead of it in the base order, of a
+// lower tier and of a lower special rank. Among ready mods of the same tier and rank it is
+// therefore lowest for the lowest index, so only the head of each such group is compared.
+func (g *ConstraintGraph) Solve() Solution {
+	n := len(g.Mods)
+	st := &solveState{
+		g:        g,
+		placed:   make([]bool, n),
+		waiting:  make([]int, n),
+		below:    newFenwick(n),
+		tierLeft: map[int]int{},
+		rankLeft: map[int]int{},
+		groups:   map[solveGroup]*modQueue{},
+		pinned:   &modQueue{less: func(a, b int) bool { return g.pinned[a] < g.pinned[b] || (g.pinned[a] == g.pinned[b] && a < b) }},
+	}
+	hardAfter := make([][]int, n)
+	for i, before := range g.hardBefore {
+		for _, j := range before {
+			if j != i {
+				st.waiting[i]++
+			}
+			hardAfter[j] = append(hardAfter[j], i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		st.tierLeft[g.tiers[i]]++
+		if g.ranks[i] >= 0 {
+			st.rankLeft[g.ranks[i]]++
+		}
+		if st.waiting[i] == 0 {
+			st.ready(i)
+		}
+	}
+
+	order := make([]int, 0, n)
+	for pos := 0; pos < n; pos++ {
+		best := st.pick(pos)
+		st.place(best)
+		order = append(order, best)
+		for _, i := range hardAfter[best] {
+			st.waiting[i]--
+			if st.waiting[i] == 0 && !st.placed[i] {
+				st.ready(i)
+			}
+		}
+	}
+
+	result := make([]*Mod, n)
+	for pos, i := range order {
+		result[pos] = g.Mods[i]
+	}
+	return Solution{Order: result, Broken: g.broken(order)}
+}
+
+// solveGroup is the tier and special rank shared by the mods of one ready queue.
+type solveGroup struct {
+	tier, rank int
+}
+
+// solveState tracks the unplaced mods while Solve fills the positions.
+type solveState struct {
+	g        *ConstraintGraph
+	placed   []bool
+	waiting  []int
+	below    fenwick
+	tierLeft map[int]int
+	rankLeft map[int]int
+	groups   map[solveGroup]*modQueue
+	pinned   *modQueue
+}
+
+// ready queues mod i, whose hard constraints are met.
+func (st *solveState) ready(i int) {
+	if st.g.pinned[i] >= 0 {
+		heap.Push(st.pinned, i)
+		return
+	}
+	key := solveGroup{st.g.tiers[i], st.g.ranks[i]}
+	q, ok := st.groups[key]
+	if !ok {
+		q = &modQueue{less: func(a, b int) bool { return a < b }}
+		st.groups[key] = q
+	}
+	heap.Push(q, i)
+}
+
+// place removes mod i from the unplaced mods.
+func (st *solveState) place(i int) {
+	st.placed[i] = true
+	st.below.add(i, -1)
+	st.tierLeft[st.g.tiers[i]]--
+	if st.g.ranks[i] >= 0 {
+		st.rankLeft[st.g.ranks[i]]--
+	}
+}
+
+// cost returns the soft weight broken by placing mod i next.
+func (st *solveState) cost(i int) int {
+	cost := st.below.prefix(i) * WeightBaseOrder
+	for tier, count := range st.tierLeft {
+		if tier < st.g.tiers[i] {
+			cost += count * WeightTagTier
+		}
+	}
+	if st.g.ranks[i] >= 0 {
+		for rank, count := range st.rankLeft {
+			if rank < st.g.ranks[i] {
+				cost += count * WeightSpecialOrder
+			}
+		}
+	}
+	return cost
+}
+
+// pick chooses and dequeues the mod for position pos.
+func (st *solveState) pick(pos int) int {
+	var bestQueue *modQueue
+	best, bestCost := -1, 0
+	for _, q := range st.groups {
+		if q.Len() == 0 {
+			continue
+		}
+		i := q.items[0]
+		if cost := st.cost(i); best < 0 || cost < bestCost || (cost == bestCost && i < best) {
+			best, bestCost, bestQueue = i, cost, q
+		}
+	}
+	if st.pinned.Len() > 0 && (st.g.pinned[st.pinned.items[0]] <= pos || best < 0) {
+		return heap.Pop(st.pinned).(int)
+	}
+	if best >= 0 {
+		return heap.Pop(bestQueue).(int)
+	}
+	// Every unplaced mod waits on another: a dependency cycle. Break it at the mod waiting on
+	// the fewest others.
+	for i := range st.g.Mods {
+		if !st.placed[i] && (best < 0 || st.waiting[i] < st.waiting[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// modQueue is a heap of mod indexes ordered by less.
+type modQueue struct {
+	items []int
+	less  func(a, b int) bool
+}
+
+func (q *modQueue) Len() int           { return len(q.items) }
+func (q *modQueue) Less(a, b int) bool { return q.less(q.items[a], q.items[b]) }
+func (q *modQueue) Swap(a, b int)      { q.items[a], q.items[b] = q.items[b], q.items[a] }
+func (q *modQueue) Push(x interface{}) { q.items = append(q.items, x.(int)) }
+func (q *modQueue) Pop() interface{} {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
+
+// fenwick counts the unplaced mods below an index.
+type fenwick []int
+
+// newFenwick returns a tree of n indexes, each counted once.
+func newFenwick(n int) fenwick {
+	f := make(fenwick, n+1)
+	for i := 1; i <= n; i++ {
+		f[i]++
+		if j := i + i&-i; j <= n {
+			f[j] += f[i]
+		}
+	}
+	return f
+}
+
+// add adds d to the count of index i.
+func (f fenwick) add(i, d int) {
+	for i++; i < len(f); i += i & -i {
+		f[i] += d
+	}
+}
+
+// prefix returns the count of the indexes below i.
+func (f fenwick) prefix(i int) int {
+	sum := 0
+	for ; i > 0; i -= i & -i {
+		sum += f[i]
+	}
+	return sum
+}
+
+// broken lists the hard and soft constraints order does not satisfy, except the base order,
+// with at most one violation per mod and kind.
+func (g *ConstraintGraph) broken(order []int) []Violation {
+	position := make([]int, len(order))
+	for pos, i := range order {
+		position[i] = pos
+	}
+	var violations []Violation
+	seen := map[string]bool{}
+	var constraints, pairs []Constraint
+	for _, c := range g.Constraints {
+		if c.Hard() {
+			constraints = append(constraints, c)
+		} else {
+			pairs = append(pairs, c)
+		}
+	}
+	pairs = append(pairs, brokenTagTiers(g.tiers, position)...)
+	// Pair constraints are reported by the earliest mod in the list that they are broken by,
+	// a tag tier ahead of a special order
+	sort.SliceStable(pairs, func(a, b int) bool {
+		if pairs[a].Before != pairs[b].Before {
+			return pairs[a].Before < pairs[b].Before
+		}
+		return pairs[a].Kind == ViolationTagTier && pairs[b].Kind != ViolationTagTier
+	})
+	constraints = append(constraints, pairs...)
+	sort.SliceStable(constraints, func(a, b int) bool {
+		return position[constraints[a].After] < position[constraints[b].After]
+	})
+	for _, c := range constraints {
+		if position[c.Before] < position[c.After] {
+			continue
+		}
+		mod := g.Mods[c.After]
+		key := mod.HashKey + "\x00" + c.Kind
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		violations = append(violations, Violation{
+			Mod:   mod,
+			Kind:  c.Kind,
+			Cause: fmt.Sprintf("placed at %d before %s at %d: %s", position[c.After], g.Mods[c.Before].Name, position[c.Before], c.Cause),
+		})
+	}
+	for pos, i := range order {
+		if want, ok := g.Pins[i]; ok && pos != want {
+			violations = append(violations, Violation{
+				Mod:   g.Mods[i],
+				Kind:  ViolationPin,
+				Cause: fmt.Sprintf("pinned to %d but placed at %d", want, pos),
+			})
+		}
+	}
+	return violations
+}
+
+// brokenTagTiers returns, for every mod placed ahead of a mod of a lower tag tier, the tag
+// tier constraint with the earliest such mod in the list. tiers and position are indexed by
+// mod, position giving the place of each mod in the order checked.
+func brokenTagTiers(tiers, position []int) []Constraint {
+	last := map[int]int{}
+	for i, tier := range tiers {
+		if p, ok := last[tier]; !ok || position[i] > p {
+			last[tier] = position[i]
+		}
+	}
+	var broken []Constraint
+	for j := range tiers {
+		late := false
+		for tier, p := range last {
+			late = late || (tier < tiers[j] && p > position[j])
+		}
+		if !late {
+			continue
+		}
+		for i := range tiers {
+			if tiers[i] < tiers[j] && position[i] > position[j] {
+				broken = append(broken, Constraint{Before: i, After: j, Kind: ViolationTagTier, Weight: WeightTagTier,
+					Cause: fmt.Sprintf("tag tier %d must load before tier %d", tiers[i], tiers[j])})
+				break
+			}
+		}
+	}
+	return broken
+}
+
+// Solve builds the constraints of modList and orders it.
+func Solve(modList []*Mod, data map[string]map[string]interface{}, idList []string, pins map[string]int, rules SortRules) Solution {
+	return BuildConstraints(modList, data, idList, pins, rules).Solve()
+}
+
+// MovedCount returns the smallest number of mods that have to move to turn before into after,
+// which is the number of mods outside their longest common subsequence.
+func MovedCount(before, after []*Mod) int {
+	position := make(map[string]int, len(before))
+	for i, mod := range before {
+		position[mod.HashKey] = i
+	}
+	var positions []int
+	for _, mod := range after {
+		if pos, ok := position[mod.HashKey]; ok {
+			positions = append(positions, pos)
+		}
+	}
+	moved := 0
+	for _, kept := range increasingSubsequence(positions) {
+		if !kept {
+			moved++
+		}
+	}
+	return moved
+}
+
+// increasingSubsequence marks the elements of one longest strictly increasing subsequence
+// of values, found by patience sorting.
+func increasingSubsequence(values []int) []bool {
+	var tails []int // index into values of the smallest tail of each length
+	parent := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= v })
+		parent[i] = -1
+		if k > 0 {
+			parent[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	kept := make([]bool, len(values))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = parent[i] {
+			kept[i] = true
+		}
+	}
+	return kept
+}
+
+// clamp limits a pinned index to the positions of a list of n mods.
+func clamp(want, n int) int {
+	if want >= n {
+		want = n - 1
+	}
+	if want < 0 {
+		want = 0
+	}
+	return want
+}
@@ -0,0 +1,270 @@
+package mods
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+func solverNames(modList []*Mod) []string {
+	var names []string
+	for _, mod := range modList {
+		names = append(names, mod.Name)
+	}
+	return names
+}
+
+func solverRegistry(names ...string) map[string]map[string]interface{} {
+	data := map[string]map[string]interface{}{}
+	for _, name := range names {
+		data[name] = map[string]interface{}{"displayName": name}
+	}
+	return data
+}
+
+func TestSolve_TagTiersAndBaseOrder(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "F", Name: "F", Tags: []string{"Fixes"}},
+		{HashKey: "G", Name: "G", Tags: []string{"Graphics"}},
+		{HashKey: "A", Name: "A"},
+		{HashKey: "B", Name: "B"},
+	}
+	solution := Solve(modList, solverRegistry("F", "G", "A", "B"), nil, nil, StellarisRules)
+	if got, want := solverNames(solution.Order), []string{"A", "B", "G", "F"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if len(solution.Broken) != 0 {
+		t.Errorf("expected no broken constraints, got %v", solution.Broken)
+	}
+}
+
+func TestSolve_DependencyOverridesTagTier(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "A", Name: "A", Dependencies: []string{"F"}},
+		{HashKey: "F", Name: "F", Tags: []string{"Fixes"}},
+	}
+	solution := Solve(modList, solverRegistry("A", "F"), nil, nil, StellarisRules)
+	if got, want := solverNames(solution.Order), []string{"F", "A"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if len(solution.Broken) != 1 || solution.Broken[0].Kind != ViolationTagTier || solution.Broken[0].Mod.Name != "F" {
+		t.Errorf("expected the tag tier of F to be reported, got %v", solution.Broken)
+	}
+}
+
+func TestSolve_SpecialOrderKeptAfterDependencies(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "Dark UI", Name: "Dark UI"},
+		{HashKey: "X", Name: "X", Dependencies: []string{"Dark UI"}},
+		{HashKey: "UI Overhaul Dynamic", Name: "UI Overhaul Dynamic"},
+	}
+	solution := Solve(modList, solverRegistry("Dark UI", "X", "UI Overhaul Dynamic"), nil, nil, StellarisRules)
+	if got, want := solverNames(solution.Order), []string{"UI Overhaul Dynamic", "Dark UI", "X"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if len(solution.Broken) != 0 {
+		t.Errorf("expected no broken constraints, got %v", solution.Broken)
+	}
+}
+
+func TestSolve_SameTagNotGrouped(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "A", Name: "A", Tags: []string{"Ships"}},
+		{HashKey: "B", Name: "B"},
+		{HashKey: "C", Name: "C", Tags: []string{"Ships"}},
+		{HashKey: "D", Name: "D", Tags: []string{"OST"}},
+		{HashKey: "E", Name: "E", Tags: []string{"OST"}},
+	}
+	solution := Solve(modList, solverRegistry("A", "B", "C", "D", "E"), nil, nil, StellarisRules)
+	// Tags only rank mods into tiers: mods sharing an untiered tag stay where they are, and
+	// mods of one tier keep their base order.
+	if got, want := solverNames(solution.Order), []string{"A", "B", "C", "D", "E"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSolve_CustomTiers(t *testing.T) {
+	rules := SortRules{EarlyTags: []string{"Graphics"}, LateTags: []string{"Fixes"}}
+	modList := []*Mod{
+		{HashKey: "a", Name: "a", Tags: []string{"OST"}},
+		{HashKey: "b", Name: "b", Tags: []string{"Fixes"}},
+		{HashKey: "c", Name: "c", Tags: []string{"Graphics"}},
+		{HashKey: "d", Name: "d"},
+	}
+	solution := Solve(modList, solverRegistry("a", "b", "c", "d"), nil, nil, rules)
+	// OST is not an early tag for these rules, so a stays in the untagged tier
+	if got, want := solverNames(solution.Order), []string{"a", "d", "c", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSolve_Dependencies(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "a", Name: "A", ModId: "1", Dependencies: []string{"B"}},
+		{HashKey: "b", Name: "B", ModId: "2"},
+		{HashKey: "c", Name: "C", ModId: "3", Dependencies: []string{"Missing"}},
+	}
+	data := map[string]map[string]interface{}{
+		"a": {"displayName": "A"},
+		"b": {"displayName": "B"},
+		"c": {"displayName": "C"},
+	}
+	solution := Solve(modList, data, []string{"1", "2", "3"}, nil, StellarisRules)
+	if got, want := solverNames(solution.Order), []string{"B", "A", "C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if len(solution.Broken) != 0 {
+		t.Errorf("expected no broken constraints, got %v", solution.Broken)
+	}
+}
+
+func TestSolve_SpecialOrder(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "Dark UI", Name: "Dark UI"},
+		{HashKey: "Other", Name: "Other"},
+		{HashKey: "UI Overhaul Dynamic", Name: "UI Overhaul Dynamic"},
+	}
+	data := solverRegistry("Dark UI", "Other", "UI Overhaul Dynamic")
+	solution := Solve(modList, data, nil, nil, StellarisRules)
+	if got, want := solverNames(solution.Order), []string{"Other", "UI Overhaul Dynamic", "Dark UI"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	solution = Solve(modList, data, nil, nil, SortRules{})
+	if got, want := solverNames(solution.Order), []string{"Dark UI", "Other", "UI Overhaul Dynamic"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the order unchanged without special names, got %v", got)
+	}
+}
+
+func TestSolve_Pins(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "a", Name: "A"},
+		{HashKey: "b", Name: "B"},
+		{HashKey: "c", Name: "C", Dependencies: []string{"B"}},
+	}
+	data := map[string]map[string]interface{}{
+		"a": {"displayName": "A"},
+		"b": {"displayName": "B"},
+		"c": {"displayName": "C"},
+	}
+	solution := Solve(modList, data, nil, map[string]int{"a": 9}, StellarisRules)
+	if got, want := solverNames(solution.Order), []string{"B", "C", "A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	// The pin can only be met by breaking a hard dependency
+	solution = Solve(modList, data, nil, map[string]int{"c": 0}, StellarisRules)
+	if got, want := solverNames(solution.Order), []string{"A", "B", "C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if len(solution.Broken) != 1 || solution.Broken[0].Kind != ViolationPin {
+		t.Errorf("expected the pin to be reported, got %v", solution.Broken)
+	}
+}
+
+func TestSolve_DependencyCycle(t *testing.T) {
+	modList := []*Mod{
+		{HashKey: "A", Name: "A", Dependencies: []string{"B"}},
+		{HashKey: "B", Name: "B", Dependencies: []string{"A"}},
+	}
+	solution := Solve(modList, solverRegistry("A", "B"), nil, nil, StellarisRules)
+	if len(solution.Order) != 2 {
+		t.Fatalf("expected both mods, got %v", solverNames(solution.Order))
+	}
+	if len(solution.Broken) != 1 || solution.Broken[0].Kind != ViolationDependency {
+		t.Errorf("expected one broken dependency, got %v", solution.Broken)
+	}
+}
+
+func TestSolve_Empty(t *testing.T) {
+	if solution := Solve(nil, nil, nil, nil, StellarisRules); len(solution.Order) != 0 || len(solution.Broken) != 0 {
+		t.Errorf("expected empty solution, got %+v", solution)
+	}
+}
+
+func TestMovedCount(t *testing.T) {
+	a, b, c, d := &Mod{HashKey: "a"}, &Mod{HashKey: "b"}, &Mod{HashKey: "c"}, &Mod{HashKey: "d"}
+	cases := []struct {
+		after []*Mod
+		want  int
+	}{
+		{[]*Mod{a, b, c, d}, 0},
+		{[]*Mod{b, c, d, a}, 1},
+		{[]*Mod{d, c, b, a}, 3},
+		{[]*Mod{b, a, d, c}, 2},
+	}
+	for _, tc := range cases {
+		if got := MovedCount([]*Mod{a, b, c, d}, tc.after); got != tc.want {
+			t.Errorf("MovedCount(%v) = %d, want %d", GetModHashKeys(tc.after), got, tc.want)
+		}
+	}
+}
+
+// syntheticRegistry builds n mods with tags and dependencies on earlier mods, the same for
+// every call.
+func syntheticRegistry(n int) ([]*Mod, map[string]map[string]interface{}, []string) {
+	r := rand.New(rand.NewSource(1))
+	tags := []string{"Gameplay", "Balance", "Species", "Ships", "Buildings", "Events", "Economy", "Graphics", "Fixes", "Patch", "AI", "Music", "Utilities"}
+	data := map[string]map[string]interface{}{}
+	var modList []*Mod
+	var idList []string
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("Synthetic Mod %04d", i)
+		mod := &Mod{
+			HashKey:   fmt.Sprintf("h%04d", i),
+			Name:      name,
+			SortedKey: name,
+			ModId:     fmt.Sprintf("mod/ugc_%d.mod", 100000+i),
+			SteamId:   fmt.Sprintf("%d", 100000+i),
+		}
+		for k := r.Intn(3); k > 0; k-- {
+			tag := tags[r.Intn(len(tags))]
+			if !contains(mod.Tags, tag) {
+				mod.Tags = append(mod.Tags, tag)
+			}
+		}
+		if i > 0 {
+			for k := r.Intn(3); k > 0; k-- {
+				mod.Dependencies = append(mod.Dependencies, fmt.Sprintf("Synthetic Mod %04d", r.Intn(i)))
+			}
+		}
+		data[mod.HashKey] = map[string]interface{}{"displayName": name, "gameRegistryId": mod.ModId, "steamId": mod.SteamId}
+		modList = append(modList, mod)
+		if r.Intn(4) > 0 {
+			idList = append(idList, mod.ModId)
+		}
+	}
+	// Registry order is reverse alphabetical, dependencies start out after their dependents
+	for i, j := 0, len(modList)-1; i < j; i, j = i+1, j-1 {
+		modList[i], modList[j] = modList[j], modList[i]
+	}
+	return modList, data, idList
+}
+
+// quietLogs discards the log lines of the benchmarked stages, which would dominate the timings.
+func quietLogs(b *testing.B) {
+	out := prettylog.Output
+	prettylog.Output = io.Discard
+	b.Cleanup(func() { prettylog.Output = out })
+}
+
+func BenchmarkSolve2000(b *testing.B) {
+	modList, data, idList := syntheticRegistry(2000)
+	quietLogs(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Solve(modList, data, idList, nil, StellarisRules)
+	}
+}
+
+func BenchmarkPlaysetSolve2000(b *testing.B) {
+	modList, data, idList := syntheticRegistry(2000)
+	quietLogs(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		solution := Solve(TweakModOrder(append([]*Mod{}, modList...)), data, idList, nil, StellarisRules)
+		CheckDisabledDependencies(solution.Order, data, idList)
+	}
+}
@@ -0,0 +1,251 @@
+package mods
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// StateFile stores the mod snapshots of the last successful sort inside the settings directory.
+const StateFile = "mod_sorter_state.json"
+
+// ModSnapshot records the descriptor and files of a mod at the time of a sort.
+type ModSnapshot struct {
+	Name       string            `json:"name"`
+	ModId      string            `json:"modId"`
+	Descriptor Descriptor        `json:"descriptor"`
+	Hash       string            `json:"hash"`
+	Files      map[string]string `json:"files"`
+}
+
+// State is the content of StateFile, keyed by HashKey.
+type State struct {
+	SortedAt time.Time               `json:"sortedAt"`
+	Mods     map[string]*ModSnapshot `json:"mods"`
+}
+
+// ModChange lists what changed in one mod between two states.
+type ModChange struct {
+	HashKey             string
+	Name                string
+	Status              string
+	VersionFrom         string
+	VersionTo           string
+	AddedDependencies   []string
+	RemovedDependencies []string
+	AddedTags           []string
+	RemovedTags         []string
+	AddedFiles          []string
+	RemovedFiles        []string
+	ModifiedFiles       []string
+	OverriddenFiles     []string
+}
+
+// Change statuses reported by DiffStates.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeUpdated = "changed"
+)
+
+// HashFile returns the hex SHA-256 of a file's content.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashModFiles hashes every file below dirPath, keyed by slash separated relative path.
+func HashModFiles(dirPath string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+		sum, err := HashFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	return files, err
+}
+
+// ContentHash combines per-file hashes into a single hash that is independent of walk order.
+func ContentHash(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		io.WriteString(h, p+" "+files[p]+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SnapshotMod reads the descriptor and hashes the files of a mod.
+func SnapshotMod(mod *Mod, data map[string]map[string]interface{}) (*ModSnapshot, error) {
+	dirPath := ModDir(data, mod)
+	snap := &ModSnapshot{Name: mod.Name, ModId: mod.ModId, Files: map[string]string{}}
+	if dirPath == "" || !isDir(dirPath) {
+		return snap, nil
+	}
+	if desc, err := DefaultCache.ReadDescriptor(ModDescriptorPath(dirPath)); err == nil {
+		snap.Descriptor = desc
+	}
+	files, err := DefaultCache.HashModFiles(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	snap.Files = files
+	snap.Hash = ContentHash(files)
+	return snap, nil
+}
+
+// TakeSnapshot snapshots every mod of modList whose ModId is in idList.
+func TakeSnapshot(modList []*Mod, idList []string, data map[string]map[string]interface{}) (*State, error) {
+	idSet := sliceToSet(idList)
+	state := &State{SortedAt: time.Now(), Mods: map[string]*ModSnapshot{}}
+	for _, mod := range modList {
+		if _, ok := idSet[mod.ModId]; !ok {
+			continue
+		}
+		snap, err := SnapshotMod(mod, data)
+		if err != nil {
+			return nil, err
+		}
+		state.Mods[mod.HashKey] = snap
+	}
+	return state, nil
+}
+
+// LoadState reads StateFile from the settings directory. A missing file yields nil without error.
+func LoadState(settingsPath string) (*State, error) {
+	content, err := os.ReadFile(filepath.Join(settingsPath, StateFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveState writes StateFile to the settings directory.
+func SaveState(settingsPath string, state *State) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(settingsPath, StateFile), content, 0644)
+}
+
+// DiffStates reports every mod that was added, removed or changed between old and cur,
+// sorted by name.
+func DiffStates(old, cur *State) []ModChange {
+	providers := map[string][]string{}
+	for h, snap := range cur.Mods {
+		for p := range snap.Files {
+			providers[p] = append(providers[p], h)
+		}
+	}
+	var changes []ModChange
+	for h, snap := range cur.Mods {
+		prev, ok := old.Mods[h]
+		if !ok {
+			changes = append(changes, ModChange{HashKey: h, Name: snap.Name, Status: ChangeAdded, VersionTo: snap.Descriptor.Version})
+			continue
+		}
+		if prev.Hash == snap.Hash && prev.Descriptor.Version == snap.Descriptor.Version {
+			continue
+		}
+		c := ModChange{
+			HashKey:             h,
+			Name:                snap.Name,
+			Status:              ChangeUpdated,
+			VersionFrom:         prev.Descriptor.Version,
+			VersionTo:           snap.Descriptor.Version,
+			AddedDependencies:   missingFrom(snap.Descriptor.Dependencies, prev.Descriptor.Dependencies),
+			RemovedDependencies: missingFrom(prev.Descriptor.Dependencies, snap.Descriptor.Dependencies),
+			AddedTags:           missingFrom(snap.Descriptor.Tags, prev.Descriptor.Tags),
+			RemovedTags:         missingFrom(prev.Descriptor.Tags, snap.Descriptor.Tags),
+		}
+		for p, sum := range snap.Files {
+			before, existed := prev.Files[p]
+			switch {
+			case !existed:
+				c.AddedFiles = append(c.AddedFiles, p)
+			case before != sum:
+				c.ModifiedFiles = append(c.ModifiedFiles, p)
+			default:
+				continue
+			}
+			if len(providers[p]) > 1 {
+				c.OverriddenFiles = append(c.OverriddenFiles, p)
+			}
+		}
+		for p := range prev.Files {
+			if _, ok := snap.Files[p]; !ok {
+				c.RemovedFiles = append(c.RemovedFiles, p)
+			}
+		}
+		sort.Strings(c.AddedFiles)
+		sort.Strings(c.ModifiedFiles)
+		sort.Strings(c.RemovedFiles)
+		sort.Strings(c.OverriddenFiles)
+		changes = append(changes, c)
+	}
+	for h, snap := range old.Mods {
+		if _, ok := cur.Mods[h]; !ok {
+			changes = append(changes, ModChange{HashKey: h, Name: snap.Name, Status: ChangeRemoved, VersionFrom: snap.Descriptor.Version})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		return changes[i].HashKey < changes[j].HashKey
+	})
+	return changes
+}
+
+// NeedsResort reports whether a change affects the load order rather than only content.
+func (c ModChange) NeedsResort() bool {
+	return c.Status != ChangeUpdated || len(c.AddedDependencies) > 0 || len(c.RemovedDependencies) > 0 ||
+		len(c.AddedTags) > 0 || len(c.RemovedTags) > 0 || len(c.OverriddenFiles) > 0
+}
+
+// missingFrom returns the entries of a that are not in b.
+func missingFrom(a, b []string) []string {
+	var result []string
+	for _, v := range a {
+		if !contains(b, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeMod(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestContentHash_OrderIndependent(t *testing.T) {
+	a := ContentHash(map[string]string{"x": "1", "y": "2"})
+	b := ContentHash(map[string]string{"y": "2", "x": "1"})
+	if a != b || a == ContentHash(map[string]string{"x": "1"}) {
+		t.Error("expected hash to depend on content only")
+	}
+}
+
+func TestSaveState_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if s, err := LoadState(dir); s != nil || err != nil {
+		t.Fatalf("expected no state, got %v, %v", s, err)
+	}
+	state := &State{Mods: map[string]*ModSnapshot{"h1": {Name: "A", Hash: "abc", Files: map[string]string{"f": "1"}}}}
+	if err := SaveState(dir, state); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(dir)
+	if err != nil || !reflect.DeepEqual(loaded.Mods, state.Mods) {
+		t.Errorf("unexpected state %+v, %v", loaded, err)
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	base := t.TempDir()
+	modA := filepath.Join(base, "a")
+	modB := filepath.Join(base, "b")
+	writeMod(t, modA, map[string]string{
+		"descriptor.mod":         "name=\"A\"\nversion=\"1\"\ntags={\n\t\"Gameplay\"\n}\n",
+		"common/buildings/a.txt": "a",
+		"events/a.txt":           "a",
+	})
+	writeMod(t, modB, map[string]string{
+		"descriptor.mod":         "name=\"B\"\n",
+		"common/buildings/b.txt": "b",
+	})
+	data := map[string]map[string]interface{}{
+		"h1": {"displayName": "A", "gameRegistryId": "mod/a.mod", "dirPath": modA},
+		"h2": {"displayName": "B", "gameRegistryId": "mod/b.mod", "dirPath": modB},
+	}
+	modList := GetModList(data)
+	idList := []string{"mod/a.mod", "mod/b.mod"}
+	old, err := TakeSnapshot(modList, idList, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := DiffStates(old, old); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+
+	writeMod(t, modA, map[string]string{
+		"descriptor.mod":         "name=\"A\"\nversion=\"2\"\ntags={\n\t\"Fixes\"\n}\ndependencies={\n\t\"B\"\n}\n",
+		"common/buildings/a.txt": "changed",
+		"common/buildings/b.txt": "override",
+	})
+	os.Remove(filepath.Join(modA, "events", "a.txt"))
+	cur, _ := TakeSnapshot(modList, []string{"mod/a.mod"}, data)
+	changes := DiffStates(old, cur)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	a := changes[0]
+	if a.Status != ChangeUpdated || a.VersionFrom != "1" || a.VersionTo != "2" {
+		t.Errorf("unexpected change %+v", a)
+	}
+	if !reflect.DeepEqual(a.AddedDependencies, []string{"B"}) || !reflect.DeepEqual(a.AddedTags, []string{"Fixes"}) || !reflect.DeepEqual(a.RemovedTags, []string{"Gameplay"}) {
+		t.Errorf("unexpected descriptor diff %+v", a)
+	}
+	if !reflect.DeepEqual(a.AddedFiles, []string{"common/buildings/b.txt"}) || !reflect.DeepEqual(a.RemovedFiles, []string{"events/a.txt"}) {
+		t.Errorf("unexpected file diff %+v", a)
+	}
+	if !a.NeedsResort() {
+		t.Error("expected dependency change to need a re-sort")
+	}
+	if changes[1].Status != ChangeRemoved || changes[1].Name != "B" {
+		t.Errorf("expected B to be reported removed, got %+v", changes[1])
+	}
+}
@@ -1,118 +1,186 @@
 package mods
 
 import (
-	"io/ioutil"
-	"path/filepath"
-	"strings"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	prettylog "stellaris-mod-sorter-go/internal/utils"
 )
 
-// parseDependencies extracts dependencies from descriptor content.
-func parseDependencies(desc string) []string {
-	var deps []string
-	inBlock := false
-	for _, line := range splitLines(desc) {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "dependencies") {
-			inBlock = true
-			continue
-		}
-		if inBlock {
-			if strings.Contains(line, "}") {
-				break
-			}
-			if line != "" {
-				deps = append(deps, trimQuotes(line))
-			}
+// CheckDependencies sets the dependencies of the mod from its parsed descriptors. A later
+// descriptor with dependencies, the .mod file, replaces those of an earlier one.
+func CheckDependencies(descs []Descriptor, mod *Mod) {
+	for _, desc := range descs {
+		if len(desc.Dependencies) > 0 {
+			mod.Dependencies = desc.Dependencies
 		}
 	}
-	return deps
 }
 
-// parseTags extracts tags from descriptor content.
-func parseTags(desc string) []string {
-	var tags []string
-	inBlock := false
-	for _, line := range splitLines(desc) {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "tags") {
-			inBlock = true
-			continue
-		}
-		if inBlock {
-			if strings.Contains(line, "}") {
-				break
+// CheckTags adds the tags of the mod's parsed descriptors to the mod and to allTags.
+func CheckTags(descs []Descriptor, mod *Mod, allTags map[string][]string) {
+	for _, desc := range descs {
+		for _, t := range desc.Tags {
+			if !contains(mod.Tags, t) {
+				mod.Tags = append(mod.Tags, t)
 			}
-			if line != "" {
-				tags = append(tags, trimQuotes(line))
+			if !contains(allTags[t], mod.SortedKey) {
+				allTags[t] = append(allTags[t], mod.SortedKey)
 			}
 		}
 	}
-	return tags
 }
 
-// CheckDependencies parses dependencies from descriptor content and updates the mod.
-func CheckDependencies(descContent []string, mod *Mod) {
-	for _, desc := range descContent {
-		if strings.Contains(desc, "dependencies") {
-			deps := parseDependencies(desc)
-			if len(deps) > 0 {
-				mod.Dependencies = deps
-			}
-		}
+// DefaultDescriptorWorkers bounds the descriptors GetModDescription reads at the same time.
+const DefaultDescriptorWorkers = 8
+
+// GetModDescription processes mods, extracting tags and dependencies from descriptor files.
+// Descriptors that cannot be read are reported as a warning and skipped. It only returns an
+// error when ctx is cancelled, see LoadModDescriptions.
+func GetModDescription(ctx context.Context, modList []*Mod, data map[string]map[string]interface{}, allTags map[string][]string, settingPath string) error {
+	err := LoadModDescriptions(ctx, modList, data, allTags, settingPath, DefaultDescriptorWorkers)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
 	}
+	if err != nil {
+		prettylog.PrintPretty("GetModDescription", "Some descriptors could not be read:\n"+err.Error(), prettylog.LogWarning)
+	}
+	return nil
 }
 
-// CheckTags parses tags from descriptor content and updates allTags.
-func CheckTags(descContent []string, mod *Mod, allTags map[string][]string) {
-	for _, desc := range descContent {
-		if strings.Contains(desc, "tags") {
-			tags := parseTags(desc)
-			for _, t := range tags {
-				if !contains(allTags[t], mod.SortedKey) {
-					allTags[t] = append(allTags[t], mod.SortedKey)
-				}
+// LoadModDescriptions reads the descriptors of modList on up to workers goroutines, then
+// applies tags and dependencies in modList order so the result does not depend on which read
+// finishes first. The descriptors are kept in the Descriptors of every mod, for the checks
+// of LintMod. It returns the read errors of every mod joined, or the context error without
+// changing any mod when ctx is cancelled first.
+func LoadModDescriptions(ctx context.Context, modList []*Mod, data map[string]map[string]interface{}, allTags map[string][]string, settingPath string, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+	descriptors := make([]*ModDescriptors, len(modList))
+	locks := newDirLocks()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(modList); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				descriptors[i] = readModDescriptors(ctx, modList[i], data, settingPath, locks)
 			}
+		}()
+	}
+feed:
+	for i := range modList {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
 		}
 	}
-}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-// GetModDescription processes mods, extracting tags and dependencies from descriptor files.
-func GetModDescription(modList []*Mod, data map[string]map[string]interface{}, allTags map[string][]string, settingPath string) {
-	for _, mod := range modList {
-		d := data[mod.HashKey]
-		dirPath, _ := d["dirPath"].(string)
-		archivePath, _ := d["archivePath"].(string)
-		descriptor := []string{}
-		if dirPath == "" || !isDir(dirPath) {
-			continue
-		}
-		descFile := filepath.Join(dirPath, "descriptor.mod")
-		if fileExists(descFile) {
-			if content, err := ioutil.ReadFile(descFile); err == nil {
-				descriptor = append(descriptor, string(content))
-			}
-		}
-		if archivePath != "" && len(descriptor) == 0 && fileExists(archivePath) {
-			if err := extractZip(archivePath, dirPath); err == nil {
-				if fileExists(descFile) {
-					if content, err := ioutil.ReadFile(descFile); err == nil {
-						descriptor = append(descriptor, string(content))
-					}
-				}
-			}
+	var errs []error
+	for i, mod := range modList {
+		mod.Descriptors = descriptors[i]
+		descs, err := descriptors[i].parsed()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", mod.Name, err))
 		}
-		if len(descriptor) == 0 {
-			continue
-		}
-		modFile := filepath.Join(settingPath, "mod", mod.ModId)
-		if fileExists(modFile) {
-			if content, err := ioutil.ReadFile(modFile); err == nil {
-				descriptor = append(descriptor, string(content))
-			}
+		if len(descs) > 0 {
+			CheckTags(descs, mod, allTags)
+			CheckDependencies(descs, mod)
 		}
-		if len(descriptor) > 0 {
-			CheckTags(descriptor, mod, allTags)
-			CheckDependencies(descriptor, mod)
+	}
+	return errors.Join(errs...)
+}
+
+// ModDescriptors holds the descriptor.mod of a mod and its .mod file in the settings
+// directory, with the errors reading them. The .mod file is only read when the descriptor is.
+type ModDescriptors struct {
+	Descriptor    Descriptor
+	DescriptorErr error
+	ModFile       Descriptor
+	ModFileErr    error
+}
+
+// parsed returns the descriptors that could be read, descriptor.mod first, and the error
+// of the one that could not. Missing files are not an error.
+func (d *ModDescriptors) parsed() ([]Descriptor, error) {
+	if d == nil || os.IsNotExist(d.DescriptorErr) {
+		return nil, nil
+	}
+	if d.DescriptorErr != nil {
+		return nil, d.DescriptorErr
+	}
+	descs := []Descriptor{d.Descriptor}
+	if os.IsNotExist(d.ModFileErr) {
+		return descs, nil
+	}
+	if d.ModFileErr != nil {
+		return descs, d.ModFileErr
+	}
+	return append(descs, d.ModFile), nil
+}
+
+// readModDescriptors reads the descriptor.mod of a mod, extracting its archive first when the
+// folder has none, followed by its .mod file. Mods without a folder yield nil. Once ctx is
+// cancelled, the descriptors are not read and the context error is recorded instead, since
+// reading them may mean extracting a whole archive.
+func readModDescriptors(ctx context.Context, mod *Mod, data map[string]map[string]interface{}, settingPath string, locks *dirLocks) *ModDescriptors {
+	d := data[mod.HashKey]
+	dirPath, _ := d["dirPath"].(string)
+	archivePath, _ := d["archivePath"].(string)
+	if dirPath == "" || !isDir(dirPath) {
+		return nil
+	}
+	// Duplicate registry entries may share a folder; only one of them extracts into it
+	defer locks.lock(dirPath)()
+	if err := ctx.Err(); err != nil {
+		return &ModDescriptors{DescriptorErr: err}
+	}
+	descs := &ModDescriptors{}
+	descFile := ModDescriptorPath(dirPath)
+	descs.Descriptor, descs.DescriptorErr = DefaultCache.ReadDescriptor(descFile)
+	if os.IsNotExist(descs.DescriptorErr) && archivePath != "" && fileExists(archivePath) {
+		if err := extractZip(archivePath, dirPath); err != nil {
+			descs.DescriptorErr = fmt.Errorf("extracting %s: %w", archivePath, err)
+			return descs
 		}
+		descs.Descriptor, descs.DescriptorErr = DefaultCache.ReadDescriptor(descFile)
+	}
+	if descs.DescriptorErr == nil {
+		descs.ModFile, descs.ModFileErr = DefaultCache.ReadDescriptor(ModFilePath(settingPath, mod))
+	}
+	return descs
+}
+
+// dirLocks hands out one mutex per mod folder.
+type dirLocks struct {
+	mu   sync.Mutex
+	held map[string]*sync.Mutex
+}
+
+func newDirLocks() *dirLocks {
+	return &dirLocks{held: map[string]*sync.Mutex{}}
+}
+
+// lock locks the mutex of dir and returns its unlock function.
+func (l *dirLocks) lock(dir string) func() {
+	l.mu.Lock()
+	m, ok := l.held[dir]
+	if !ok {
+		m = &sync.Mutex{}
+		l.held[dir] = m
 	}
+	l.mu.Unlock()
+	m.Lock()
+	return m.Unlock
 }
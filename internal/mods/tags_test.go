@@ -1,20 +1,19 @@
 package mods
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
 func TestCheckTags(t *testing.T) {
-	descContent := []string{
-		`tags={
-	"UI"
-	"Overhaul"
-}`,
-	}
+	descContent := []Descriptor{{Tags: []string{"UI", "Overhaul"}}}
 	allTags := make(map[string][]string)
 	mod := &Mod{SortedKey: "mod1"}
 	CheckTags(descContent, mod, allTags)
@@ -24,16 +23,11 @@ func TestCheckTags(t *testing.T) {
 }
 
 func TestCheckDependencies(t *testing.T) {
-	descContent := []string{
-		`dependencies={
-	"modA"
-	"modB"
-}`,
-	}
+	descContent := []Descriptor{{Dependencies: []string{"modA", "modB"}}, {}, {Dependencies: []string{"modC"}}}
 	mod := &Mod{ModId: "mod1"}
 	CheckDependencies(descContent, mod)
-	if !reflect.DeepEqual(mod.Dependencies, []string{"modA", "modB"}) {
-		t.Errorf("CheckDependencies failed: got %v", mod.Dependencies)
+	if !reflect.DeepEqual(mod.Dependencies, []string{"modC"}) {
+		t.Errorf("expected the .mod file dependencies to win, got %v", mod.Dependencies)
 	}
 }
 
@@ -56,7 +50,7 @@ dependencies={
 		"h1": {"dirPath": dir, "archivePath": ""},
 	}
 	allTags := make(map[string][]string)
-	GetModDescription(modList, data, allTags, dir)
+	GetModDescription(context.Background(), modList, data, allTags, dir)
 	if !reflect.DeepEqual(allTags["UI"], []string{"mod1"}) {
 		t.Errorf("GetModDescription tags failed: got %v", allTags)
 	}
@@ -64,3 +58,95 @@ dependencies={
 		t.Errorf("GetModDescription dependencies failed: got %v", modList[0].Dependencies)
 	}
 }
+
+func TestGetModDescription_SingleLineBlocks(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "descriptor.mod"), []byte("name=\"One Line\"\ntags={\"UI\" \"Fixes\"}\ndependencies={ \"modA\" }\n"), 0644)
+	modList := []*Mod{{ModId: "mod1", HashKey: "h1", SortedKey: "mod1"}}
+	data := map[string]map[string]interface{}{"h1": {"dirPath": dir}}
+	GetModDescription(context.Background(), modList, data, map[string][]string{}, dir)
+	if !reflect.DeepEqual(modList[0].Tags, []string{"UI", "Fixes"}) || !reflect.DeepEqual(modList[0].Dependencies, []string{"modA"}) {
+		t.Errorf("expected the tags and dependencies of single-line blocks, got %v and %v", modList[0].Tags, modList[0].Dependencies)
+	}
+}
+
+func TestCheckTags_SetsModTags(t *testing.T) {
+	descContent := []Descriptor{{Tags: []string{"UI", "UI", "Fixes"}}}
+	mod := &Mod{SortedKey: "mod1"}
+	CheckTags(descContent, mod, make(map[string][]string))
+	if !reflect.DeepEqual(mod.Tags, []string{"UI", "Fixes"}) {
+		t.Errorf("expected mod tags [UI Fixes], got %v", mod.Tags)
+	}
+}
+
+// writeDescribedMods creates n mod folders whose descriptors share the tag "Shared".
+func writeDescribedMods(t *testing.T, n int) ([]*Mod, map[string]map[string]interface{}) {
+	t.Helper()
+	dir := t.TempDir()
+	var modList []*Mod
+	data := map[string]map[string]interface{}{}
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("mod%03d", i)
+		modDir := filepath.Join(dir, key)
+		os.Mkdir(modDir, 0755)
+		desc := fmt.Sprintf("tags={\n\t\"Shared\"\n\t\"Tag%d\"\n}\ndependencies={\n\t\"dep%d\"\n}\n", i%3, i)
+		os.WriteFile(filepath.Join(modDir, "descriptor.mod"), []byte(desc), 0644)
+		modList = append(modList, &Mod{ModId: key, HashKey: key, Name: key, SortedKey: key})
+		data[key] = map[string]interface{}{"dirPath": modDir}
+	}
+	return modList, data
+}
+
+func TestLoadModDescriptions_Deterministic(t *testing.T) {
+	modList, data := writeDescribedMods(t, 40)
+	sequential := map[string][]string{}
+	if err := LoadModDescriptions(context.Background(), modList, data, sequential, t.TempDir(), 1); err != nil {
+		t.Fatalf("LoadModDescriptions failed: %v", err)
+	}
+	for run := 0; run < 5; run++ {
+		var parallelList []*Mod
+		for _, mod := range modList {
+			parallelList = append(parallelList, &Mod{ModId: mod.ModId, HashKey: mod.HashKey, Name: mod.Name, SortedKey: mod.SortedKey})
+		}
+		parallel := map[string][]string{}
+		if err := LoadModDescriptions(context.Background(), parallelList, data, parallel, t.TempDir(), 8); err != nil {
+			t.Fatalf("LoadModDescriptions failed: %v", err)
+		}
+		if !reflect.DeepEqual(parallel, sequential) {
+			t.Fatalf("run %d: tags differ from the sequential load", run)
+		}
+		for i, mod := range parallelList {
+			if !reflect.DeepEqual(mod.Dependencies, modList[i].Dependencies) || !reflect.DeepEqual(mod.Tags, modList[i].Tags) {
+				t.Fatalf("run %d: %s differs: %+v vs %+v", run, mod.Name, mod, modList[i])
+			}
+		}
+	}
+}
+
+func TestLoadModDescriptions_Errors(t *testing.T) {
+	modList, data := writeDescribedMods(t, 3)
+	// A directory where the descriptor should be cannot be read
+	badDesc := filepath.Join(data["mod001"]["dirPath"].(string), "descriptor.mod")
+	os.Remove(badDesc)
+	os.Mkdir(badDesc, 0755)
+	err := LoadModDescriptions(context.Background(), modList, data, map[string][]string{}, t.TempDir(), 4)
+	if err == nil || !strings.Contains(err.Error(), "mod001") {
+		t.Fatalf("expected an error naming mod001, got %v", err)
+	}
+	if len(modList[0].Tags) == 0 || len(modList[2].Tags) == 0 || len(modList[1].Tags) != 0 {
+		t.Errorf("expected the readable mods to be described, got %+v %+v %+v", modList[0], modList[1], modList[2])
+	}
+}
+
+func TestLoadModDescriptions_Cancelled(t *testing.T) {
+	modList, data := writeDescribedMods(t, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	allTags := map[string][]string{}
+	if err := LoadModDescriptions(ctx, modList, data, allTags, t.TempDir(), 4); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(allTags) != 0 || modList[0].Tags != nil {
+		t.Errorf("expected no mod to change after cancellation, got %v", allTags)
+	}
+}
@@ -25,12 +25,6 @@ func splitLines(s string) []string {
 	return strings.Split(s, "\n")
 }
 
-func trimQuotes(s string) string {
-	s = strings.TrimSpace(s)
-	s = strings.Trim(s, "\"")
-	return s
-}
-
 func isDir(path string) bool {
 	info, err := os.Stat(path)
 	return err == nil && info.IsDir()
@@ -113,18 +113,6 @@ func TestSplitLines_Empty(t *testing.T) {
 	}
 }
 
-func TestTrimQuotes(t *testing.T) {
-	if trimQuotes("  \"hello\"  ") != "hello" {
-		t.Error("trimQuotes did not trim quotes and spaces correctly")
-	}
-}
-
-func TestTrimQuotes_NoQuotes(t *testing.T) {
-	if trimQuotes("hello") != "hello" {
-		t.Error("trimQuotes should not alter string without quotes")
-	}
-}
-
 func TestIsDirAndFileExists(t *testing.T) {
 	dir := t.TempDir()
 	if !isDir(dir) {
@@ -0,0 +1,60 @@
+package mods
+
+import (
+	"strconv"
+
+	"stellaris-mod-sorter-go/internal/steam"
+)
+
+// registrySteamId returns the steamId of a registry entry, which may be stored as a string or a number.
+func registrySteamId(d map[string]interface{}) string {
+	switch v := d["steamId"].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return ""
+}
+
+// WorkshopLibraries returns the Steam libraries that hold the registry's workshop mods.
+func WorkshopLibraries(data map[string]map[string]interface{}) []string {
+	var libraries []string
+	for _, d := range data {
+		dirPath, _ := d["dirPath"].(string)
+		if library, ok := steam.LibraryFromContentPath(dirPath); ok && !contains(libraries, library) {
+			libraries = append(libraries, library)
+		}
+	}
+	return libraries
+}
+
+// AttachWorkshopInfo sets Workshop on every mod whose SteamId is among items.
+func AttachWorkshopInfo(modList []*Mod, items map[string]*steam.WorkshopItem) {
+	for _, mod := range modList {
+		if mod.SteamId == "" {
+			continue
+		}
+		if it, ok := items[mod.SteamId]; ok {
+			mod.Workshop = it
+		}
+	}
+}
+
+// UnregisteredWorkshopItems returns the IDs of installed or subscribed workshop items
+// that have no entry in mods_registry.json.
+func UnregisteredWorkshopItems(data map[string]map[string]interface{}, items map[string]*steam.WorkshopItem) []string {
+	registered := map[string]bool{}
+	for _, d := range data {
+		if id := registrySteamId(d); id != "" {
+			registered[id] = true
+		}
+	}
+	var missing []string
+	for _, id := range steam.SortedIds(items) {
+		if !registered[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,49 @@
+package mods
+
+import (
+	"reflect"
+	"testing"
+
+	"stellaris-mod-sorter-go/internal/steam"
+)
+
+func TestAttachWorkshopInfo(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"h1": {"displayName": "A", "gameRegistryId": "mod/ugc_111.mod", "steamId": "111"},
+		"h2": {"displayName": "B", "gameRegistryId": "mod/ugc_222.mod", "steamId": 222.0},
+		"h3": {"displayName": "C", "gameRegistryId": "mod/local.mod"},
+	}
+	items := map[string]*steam.WorkshopItem{
+		"111": {PublishedFileId: "111", Size: 10},
+		"222": {PublishedFileId: "222", Size: 20},
+		"333": {PublishedFileId: "333"},
+	}
+	modList := GetModList(data)
+	AttachWorkshopInfo(modList, items)
+	for _, mod := range modList {
+		switch mod.HashKey {
+		case "h1", "h2":
+			if mod.Workshop == nil || mod.Workshop.PublishedFileId != mod.SteamId {
+				t.Errorf("expected workshop info on %s, got %+v", mod.Name, mod.Workshop)
+			}
+		case "h3":
+			if mod.Workshop != nil {
+				t.Errorf("expected no workshop info on local mod")
+			}
+		}
+	}
+	if got := UnregisteredWorkshopItems(data, items); !reflect.DeepEqual(got, []string{"333"}) {
+		t.Errorf("expected unregistered [333], got %v", got)
+	}
+}
+
+func TestWorkshopLibraries(t *testing.T) {
+	data := map[string]map[string]interface{}{
+		"h1": {"dirPath": "/lib/steamapps/workshop/content/281990/1"},
+		"h2": {"dirPath": "/lib/steamapps/workshop/content/281990/2"},
+		"h3": {"dirPath": "/home/u/mods/local"},
+	}
+	if got := WorkshopLibraries(data); len(got) != 1 {
+		t.Errorf("expected one library, got %v", got)
+	}
+}
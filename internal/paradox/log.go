@@ -0,0 +1,88 @@
+package paradox
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// LogEntry is one message of a game log such as error.log or game.log.
+type LogEntry struct {
+	Line    int
+	Time    string
+	Source  string
+	Message string
+	Files   []string
+}
+
+var (
+	logLine = regexp.MustCompile(`^\[(\d\d:\d\d:\d\d)\]\[([^\]]*)\]:?\s?(.*)$`)
+
+	// Script, localisation and asset files referenced by log messages, quoted or bare
+	logFileExt    = `(?:txt|yml|gui|gfx|asset|dds|png|tga|mesh|anim|shader|fxh|csv|lua|mod|json)`
+	quotedLogFile = regexp.MustCompile(`"([^"]+\.` + logFileExt + `)"`)
+	bareLogFile   = regexp.MustCompile(`(?i)(?:^|[\s:=(',])((?:[a-z]:)?[\w\-.~/\\]*[/\\][\w\-.]+\.` + logFileExt + `)\b`)
+
+	errorWords = []string{"error", "failed", "invalid", "unknown", "missing", "unexpected", "duplicate"}
+)
+
+// ParseLog reads the entries of a game log. Lines without the [time][source] prefix
+// continue the previous entry.
+func ParseLog(r io.Reader) ([]LogEntry, error) {
+	var entries []LogEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		if m := logLine.FindStringSubmatch(text); m != nil {
+			entries = append(entries, LogEntry{Line: line, Time: m[1], Source: m[2], Message: m[3]})
+			continue
+		}
+		if strings.TrimSpace(text) == "" || len(entries) == 0 {
+			continue
+		}
+		last := &entries[len(entries)-1]
+		last.Message += "\n" + text
+	}
+	for i := range entries {
+		entries[i].Files = LogFiles(entries[i].Message)
+	}
+	return entries, scanner.Err()
+}
+
+// LogFiles returns the file paths referenced by a log message, with forward slashes,
+// in order of appearance and without duplicates.
+func LogFiles(message string) []string {
+	var files []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		path = strings.ReplaceAll(strings.TrimSpace(path), "\\", "/")
+		if path != "" && !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	for _, m := range quotedLogFile.FindAllStringSubmatch(message, -1) {
+		add(m[1])
+	}
+	rest := quotedLogFile.ReplaceAllString(message, " ")
+	for _, m := range bareLogFile.FindAllStringSubmatch(rest, -1) {
+		add(m[1])
+	}
+	return files
+}
+
+// IsError reports whether the message reads like an error rather than progress output.
+func (e LogEntry) IsError() bool {
+	message := strings.ToLower(e.Message)
+	for _, w := range errorWords {
+		if strings.Contains(message, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package paradox
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLog = `[14:32:10][trigger_impl.cpp:1234]: Error: "Unknown trigger type: foo" in file: events/my_events.txt line: 45
+[14:32:11][persistent.cpp:48]: Error: "Unexpected token: x, near line: 3" in file: "common/buildings/00 my buildings.txt" near line: 3
+[14:32:12][localization.cpp:312]: Duplicate localization key. Key: MY_KEY in localisation\english\my_l_english.yml
+  continued on this line with gfx/interface/icons/foo.dds
+[14:32:13][gamestate.cpp:77]: Loading 12 modules
+`
+
+func TestParseLog(t *testing.T) {
+	entries, err := ParseLog(strings.NewReader(testLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	if entries[0].Time != "14:32:10" || entries[0].Source != "trigger_impl.cpp:1234" || entries[0].Line != 1 {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	want := [][]string{
+		{"events/my_events.txt"},
+		{"common/buildings/00 my buildings.txt"},
+		{"localisation/english/my_l_english.yml", "gfx/interface/icons/foo.dds"},
+		nil,
+	}
+	for i, e := range entries {
+		if !reflect.DeepEqual(e.Files, want[i]) {
+			t.Errorf("entry %d: expected files %v, got %v", i, want[i], e.Files)
+		}
+	}
+	if !entries[2].IsError() || entries[3].IsError() {
+		t.Error("expected the duplicate key to be an error and the loading message not")
+	}
+}
+
+func TestLogFiles_AbsolutePaths(t *testing.T) {
+	files := LogFiles(`Failed to read file C:\Steam\steamapps\workshop\content\281990\123\common\x.txt and /home/u/mod/y/events/e.txt`)
+	want := []string{"C:/Steam/steamapps/workshop/content/281990/123/common/x.txt", "/home/u/mod/y/events/e.txt"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+}
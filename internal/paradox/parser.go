@@ -0,0 +1,290 @@
+package paradox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Node is one statement of a Paradox script file.
+// Assignments have a Key, an Op and either a Value or a block of Children;
+// bare list entries such as the items of tags={ ... } only have a Value.
+type Node struct {
+	Key      string
+	Op       string
+	Value    string
+	Quoted   bool
+	Block    bool
+	Children []*Node
+	Line     int
+}
+
+// Get returns the first child assigned to key, matching case-insensitively.
+func (n *Node) Get(key string) *Node {
+	for _, c := range n.Children {
+		if c.Key != "" && strings.EqualFold(c.Key, key) {
+			return c
+		}
+	}
+	return nil
+}
+
+// All returns every child assigned to key.
+func (n *Node) All(key string) []*Node {
+	var result []*Node
+	for _, c := range n.Children {
+		if c.Key != "" && strings.EqualFold(c.Key, key) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// String returns the value assigned to key, or "" when it is missing or a block.
+func (n *Node) String(key string) string {
+	if c := n.Get(key); c != nil && !c.Block {
+		return c.Value
+	}
+	return ""
+}
+
+// Strings returns the bare entries of the block assigned to key.
+func (n *Node) Strings(key string) []string {
+	c := n.Get(key)
+	if c == nil {
+		return nil
+	}
+	var result []string
+	for _, item := range c.Children {
+		if item.Key == "" && !item.Block {
+			result = append(result, item.Value)
+		}
+	}
+	return result
+}
+
+// Walk calls fn for every node below n, depth first.
+func (n *Node) Walk(fn func(*Node)) {
+	for _, c := range n.Children {
+		fn(c)
+		c.Walk(fn)
+	}
+}
+
+// Parse reads a Paradox script and returns a root block holding its top-level statements.
+func Parse(r io.Reader) (*Node, error) {
+	p := &parser{r: bufio.NewReader(r), line: 1}
+	root := &Node{Block: true}
+	if err := p.parseBlock(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// ParseString is Parse for in-memory content.
+func ParseString(s string) (*Node, error) {
+	return Parse(strings.NewReader(s))
+}
+
+type token struct {
+	text   string
+	quoted bool
+	line   int
+}
+
+type parser struct {
+	r       *bufio.Reader
+	line    int
+	pending *token
+}
+
+func isOperator(t token) bool {
+	if t.quoted {
+		return false
+	}
+	switch t.text {
+	case "=", "==", "!=", "<", ">", "<=", ">=", "?=":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseBlock(parent *Node, nested bool) error {
+	for {
+		t, err := p.next()
+		if err == io.EOF {
+			if nested {
+				return fmt.Errorf("paradox: line %d: missing }", p.line)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !t.quoted && t.text == "}" {
+			if !nested {
+				return fmt.Errorf("paradox: line %d: unexpected }", t.line)
+			}
+			return nil
+		}
+		if isOperator(t) {
+			return fmt.Errorf("paradox: line %d: unexpected %s", t.line, t.text)
+		}
+		node := &Node{Line: t.line}
+		if !t.quoted && t.text == "{" {
+			node.Block = true
+			if err := p.parseBlock(node, true); err != nil {
+				return err
+			}
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		op, err := p.next()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == nil && isOperator(op) {
+			node.Key = t.text
+			node.Op = op.text
+			val, err := p.next()
+			if err != nil {
+				return fmt.Errorf("paradox: line %d: missing value for %s", op.line, t.text)
+			}
+			if !val.quoted && val.text == "{" {
+				node.Block = true
+				if err := p.parseBlock(node, true); err != nil {
+					return err
+				}
+			} else if !val.quoted && val.text == "}" {
+				return fmt.Errorf("paradox: line %d: missing value for %s", val.line, t.text)
+			} else {
+				node.Value = val.text
+				node.Quoted = val.quoted
+				// Typed values such as rgb { 1 2 3 } keep their block as children
+				next, err := p.next()
+				if err != nil && err != io.EOF {
+					return err
+				}
+				if err == nil {
+					if !next.quoted && next.text == "{" && !val.quoted {
+						if err := p.parseBlock(node, true); err != nil {
+							return err
+						}
+					} else {
+						p.pending = &next
+					}
+				}
+			}
+		} else {
+			if err == nil {
+				p.pending = &op
+			}
+			node.Value = t.text
+			node.Quoted = t.quoted
+		}
+		parent.Children = append(parent.Children, node)
+	}
+}
+
+func (p *parser) next() (token, error) {
+	if p.pending != nil {
+		t := *p.pending
+		p.pending = nil
+		return t, nil
+	}
+	for {
+		c, err := p.read()
+		if err != nil {
+			return token{}, err
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\uFEFF':
+			continue
+		case c == '#':
+			for c != '\n' {
+				if c, err = p.read(); err != nil {
+					return token{}, err
+				}
+			}
+			continue
+		case c == '{' || c == '}':
+			return token{text: string(c), line: p.line}, nil
+		case c == '=' || c == '<' || c == '>' || c == '!' || c == '?':
+			text := string(c)
+			if n, err := p.read(); err == nil {
+				if n == '=' {
+					text += "="
+				} else {
+					p.unread(n)
+				}
+			}
+			return token{text: text, line: p.line}, nil
+		case c == '"':
+			return p.quoted()
+		default:
+			return p.bare(c)
+		}
+	}
+}
+
+func (p *parser) read() (rune, error) {
+	c, _, err := p.r.ReadRune()
+	if c == '\n' {
+		p.line++
+	}
+	return c, err
+}
+
+func (p *parser) unread(c rune) {
+	p.r.UnreadRune()
+	if c == '\n' {
+		p.line--
+	}
+}
+
+func (p *parser) quoted() (token, error) {
+	line := p.line
+	var sb strings.Builder
+	for {
+		c, err := p.read()
+		if err != nil {
+			return token{}, fmt.Errorf("paradox: line %d: unterminated string", line)
+		}
+		if c == '\\' {
+			n, err := p.read()
+			if err != nil {
+				return token{}, fmt.Errorf("paradox: line %d: unterminated string", line)
+			}
+			if n != '"' && n != '\\' {
+				sb.WriteRune(c)
+			}
+			sb.WriteRune(n)
+			continue
+		}
+		if c == '"' {
+			return token{text: sb.String(), quoted: true, line: line}, nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+func (p *parser) bare(first rune) (token, error) {
+	line := p.line
+	var sb strings.Builder
+	sb.WriteRune(first)
+	for {
+		c, err := p.read()
+		if err == io.EOF {
+			return token{text: sb.String(), line: line}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+		if strings.ContainsRune(" \t\r\n{}=<>!?#\"", c) {
+			p.unread(c)
+			return token{text: sb.String(), line: line}, nil
+		}
+		sb.WriteRune(c)
+	}
+}
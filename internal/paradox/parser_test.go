@@ -0,0 +1,72 @@
+package paradox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_Descriptor(t *testing.T) {
+	src := `name="SomeMod" # comment
+path="mod/SomeMod"
+dependencies={
+	"othermod"
+	"another mod"
+}
+tags={ "Graphics" Economy }
+remote_file_id=1234567890`
+	root, err := ParseString(src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if root.String("name") != "SomeMod" || root.String("remote_file_id") != "1234567890" {
+		t.Errorf("unexpected fields %+v", root.Children)
+	}
+	if got := root.Strings("dependencies"); !reflect.DeepEqual(got, []string{"othermod", "another mod"}) {
+		t.Errorf("unexpected dependencies %v", got)
+	}
+	if got := root.Strings("TAGS"); !reflect.DeepEqual(got, []string{"Graphics", "Economy"}) {
+		t.Errorf("unexpected tags %v", got)
+	}
+}
+
+func TestParse_ScriptOperators(t *testing.T) {
+	src := `event = {
+	trigger = {
+		has_dlc = "Federations"
+		num_pops >= 10
+		NOT = { host_has_dlc = Utopia }
+	}
+	color = rgb { 1 2 3 }
+}`
+	root, err := ParseString(src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	var dlcs []string
+	root.Walk(func(n *Node) {
+		if n.Key == "has_dlc" || n.Key == "host_has_dlc" {
+			dlcs = append(dlcs, n.Value)
+		}
+	})
+	if !reflect.DeepEqual(dlcs, []string{"Federations", "Utopia"}) {
+		t.Errorf("unexpected dlcs %v", dlcs)
+	}
+	trigger := root.Get("event").Get("trigger")
+	if n := trigger.Get("num_pops"); n == nil || n.Op != ">=" || n.Value != "10" {
+		t.Errorf("unexpected comparison %+v", n)
+	}
+	if c := root.Get("event").Get("color"); c.Value != "rgb" || len(c.Children) != 3 {
+		t.Errorf("unexpected typed value %+v", c)
+	}
+	if trigger.Get("has_dlc").Line != 3 {
+		t.Errorf("expected line 3, got %d", trigger.Get("has_dlc").Line)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, src := range []string{"a = {", "a = }", "}", `a = "x`, "= b"} {
+		if _, err := ParseString(src); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
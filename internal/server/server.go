@@ -0,0 +1,325 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"stellaris-mod-sorter-go/internal/mods"
+	prettylog "stellaris-mod-sorter-go/internal/utils"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// ModView is the JSON representation of a mod returned by the API.
+type ModView struct {
+	HashKey      string   `json:"hashKey"`
+	Name         string   `json:"name"`
+	ModId        string   `json:"modId"`
+	Tags         []string `json:"tags"`
+	Dependencies []string `json:"dependencies"`
+	Enabled      bool     `json:"enabled"`
+	Pin          *int     `json:"pin,omitempty"`
+}
+
+// Server exposes the load order of one settings directory over HTTP.
+// Every request reloads the launcher files; writes and sorts are serialized. The API only
+// answers requests addressed to a loopback host, so a DNS rebinding page cannot read it, and
+// only accepts writes with a JSON body from its own origin, so other websites cannot post to it.
+type Server struct {
+	settingsPath string
+	rules        mods.SortRules
+	mu           sync.RWMutex
+	mux          *http.ServeMux
+}
+
+// New creates a Server for the given settings directory, sorting with rules.
+func New(settingsPath string, rules mods.SortRules) *Server {
+	s := &Server{settingsPath: settingsPath, rules: rules, mux: http.NewServeMux()}
+	web, _ := fs.Sub(webFiles, "web")
+	s.mux.Handle("/", http.FileServer(http.FS(web)))
+	s.mux.HandleFunc("/api/mods", s.sorted(s.handleMods))
+	s.mux.HandleFunc("/api/tags", s.sorted(s.handleTags))
+	s.mux.HandleFunc("/api/order/current", s.read(s.handleCurrentOrder))
+	s.mux.HandleFunc("/api/order/proposed", s.sorted(s.handleProposedOrder))
+	s.mux.HandleFunc("/api/dry-run", s.sorted(s.handleProposedOrder))
+	s.mux.HandleFunc("/api/sort", s.write(s.handleSort))
+	s.mux.HandleFunc("/api/pins", s.write(s.handlePins))
+	s.mux.HandleFunc("/api/enabled", s.write(s.handleEnabled))
+	s.mux.HandleFunc("/api/backups/restore", s.write(s.handleRestore))
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type handler func(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int)
+
+// read wraps a GET handler with a shared lock and a freshly loaded playset.
+func (s *Server) read(h handler) http.HandlerFunc {
+	return s.wrap(http.MethodGet, h, false)
+}
+
+// sorted wraps a GET handler that sorts the mods with an exclusive lock, since reading the
+// descriptors may extract mod archives.
+func (s *Server) sorted(h handler) http.HandlerFunc {
+	return s.wrap(http.MethodGet, h, true)
+}
+
+// write wraps a POST handler with an exclusive lock and a freshly loaded playset.
+func (s *Server) write(h handler) http.HandlerFunc {
+	return s.wrap(http.MethodPost, h, true)
+}
+
+func (s *Server) wrap(method string, h handler, exclusive bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeJSON(w, http.StatusMethodNotAllowed, apiError("method not allowed"))
+			return
+		}
+		if !isLoopbackHost(r.Host) {
+			writeJSON(w, http.StatusForbidden, apiError("host "+r.Host+" is not a loopback address"))
+			return
+		}
+		if method == http.MethodPost {
+			if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+				writeJSON(w, http.StatusForbidden, apiError("cross-origin request from "+origin))
+				return
+			}
+			if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+				writeJSON(w, http.StatusUnsupportedMediaType, apiError("expected Content-Type: application/json"))
+				return
+			}
+		}
+		if exclusive {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+		} else {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+		}
+		p, err := mods.LoadPlayset(s.settingsPath)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, apiError(err.Error()))
+			return
+		}
+		p.Rules = s.rules
+		body, status := h(w, r, p)
+		writeJSON(w, status, body)
+	}
+}
+
+func (s *Server) handleMods(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	modList, err := p.ProposedOrder(r.Context(), p.EnabledIds())
+	if err != nil {
+		return sortError(err)
+	}
+	return s.views(p, p.CurrentOrder(modList)), http.StatusOK
+}
+
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	modList, err := p.ProposedOrder(r.Context(), p.EnabledIds())
+	if err != nil {
+		return sortError(err)
+	}
+	tags := map[string][]string{}
+	for _, mod := range modList {
+		for _, t := range mod.Tags {
+			tags[t] = append(tags[t], mod.Name)
+		}
+	}
+	return tags, http.StatusOK
+}
+
+func (s *Server) handleCurrentOrder(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	return s.views(p, p.CurrentOrder(mods.GetModList(p.Registry))), http.StatusOK
+}
+
+func (s *Server) handleProposedOrder(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	modList, err := p.ProposedOrder(r.Context(), p.EnabledIds())
+	if err != nil {
+		return sortError(err)
+	}
+	return s.views(p, modList), http.StatusOK
+}
+
+func (s *Server) handleSort(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	idList := p.EnabledIds()
+	if len(idList) == 0 {
+		return apiError("no enabled_mods found in dlc_load.json"), http.StatusConflict
+	}
+	if err := mods.ValidateLauncherFiles(p.SettingsPath); err != nil {
+		return apiError(err.Error()), http.StatusUnprocessableEntity
+	}
+	modList, err := p.ProposedOrder(r.Context(), idList)
+	if err != nil {
+		return sortError(err)
+	}
+	p.Command = "serve: sort"
+	p.Write(modList, idList, mods.BakExt)
+	if err := p.RecordState(modList, idList); err != nil {
+		prettylog.PrintError("serve", err, "Could not record mod state", false)
+	}
+	prettylog.PrintPretty("serve", "Sorted and wrote load order", prettylog.LogInfo)
+	return s.views(p, modList), http.StatusOK
+}
+
+// pinRequest pins HashKey to Index, or removes the pin when Index is nil.
+type pinRequest struct {
+	HashKey string `json:"hashKey"`
+	Index   *int   `json:"index"`
+}
+
+func (s *Server) handlePins(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	var req pinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.HashKey == "" {
+		return apiError("expected {\"hashKey\": ..., \"index\": ...}"), http.StatusBadRequest
+	}
+	if _, ok := p.Registry[req.HashKey]; !ok {
+		return apiError("unknown mod " + req.HashKey), http.StatusNotFound
+	}
+	pins, err := mods.LoadPins(p.SettingsPath)
+	if err != nil {
+		return apiError(err.Error()), http.StatusInternalServerError
+	}
+	if req.Index == nil {
+		delete(pins, req.HashKey)
+	} else {
+		pins[req.HashKey] = *req.Index
+	}
+	if err := mods.SavePins(p.SettingsPath, pins); err != nil {
+		return apiError(err.Error()), http.StatusInternalServerError
+	}
+	return pins, http.StatusOK
+}
+
+// enabledRequest enables or disables the mod with HashKey.
+type enabledRequest struct {
+	HashKey string `json:"hashKey"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (s *Server) handleEnabled(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	var req enabledRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.HashKey == "" {
+		return apiError("expected {\"hashKey\": ..., \"enabled\": ...}"), http.StatusBadRequest
+	}
+	modList := p.CurrentOrder(mods.GetModList(p.Registry))
+	var target *mods.Mod
+	for _, mod := range modList {
+		if mod.HashKey == req.HashKey {
+			target = mod
+		}
+	}
+	if target == nil {
+		return apiError("unknown mod " + req.HashKey), http.StatusNotFound
+	}
+	var idList []string
+	for _, id := range p.EnabledIds() {
+		if id != target.ModId {
+			idList = append(idList, id)
+		}
+	}
+	if req.Enabled {
+		idList = append(idList, target.ModId)
+	}
+	p.Command = "serve: enable " + target.Name
+	if !req.Enabled {
+		p.Command = "serve: disable " + target.Name
+	}
+	p.Write(modList, idList, mods.BakExt)
+	return s.views(p, modList), http.StatusOK
+}
+
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request, p *mods.Playset) (interface{}, int) {
+	files := []string{p.DlcLoadPath, p.GameDataPath}
+	// Restore neither file unless both have a backup
+	for _, file := range files {
+		if _, err := os.Stat(file + mods.BakExt); err != nil {
+			return apiError("no backup found: " + file + mods.BakExt), http.StatusConflict
+		}
+	}
+	restored := []string{}
+	change := mods.BeginChange("serve: restore backups", p.DlcLoadPath, p.GameDataPath)
+	defer func() {
+		if _, err := change.Commit(p.SettingsPath); err != nil {
+			prettylog.PrintError("serve", err, "Could not journal the change", false)
+		}
+	}()
+	for _, file := range files {
+		if err := mods.RestoreBackup(file, mods.BakExt); err != nil {
+			return apiError(err.Error()), http.StatusInternalServerError
+		}
+		restored = append(restored, file)
+	}
+	return map[string][]string{"restored": restored}, http.StatusOK
+}
+
+func (s *Server) views(p *mods.Playset, modList []*mods.Mod) []ModView {
+	enabled := map[string]bool{}
+	for _, id := range p.EnabledIds() {
+		enabled[id] = true
+	}
+	pins, _ := mods.LoadPins(p.SettingsPath)
+	views := make([]ModView, 0, len(modList))
+	for _, mod := range modList {
+		v := ModView{
+			HashKey:      mod.HashKey,
+			Name:         mod.Name,
+			ModId:        mod.ModId,
+			Tags:         mod.Tags,
+			Dependencies: mod.Dependencies,
+			Enabled:      enabled[mod.ModId],
+		}
+		if idx, ok := pins[mod.HashKey]; ok {
+			v.Pin = &idx
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+// isLoopbackHost reports whether the Host header names localhost or a loopback address.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameOrigin reports whether the Origin header is the origin of the page served from host.
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Scheme == "http" && strings.EqualFold(u.Host, host)
+}
+
+// sortError answers a sort that stopped because the request was cancelled, in practice
+// because the client went away before the descriptors were read.
+func sortError(err error) (interface{}, int) {
+	return apiError("sort cancelled: " + err.Error()), http.StatusServiceUnavailable
+}
+
+func apiError(msg string) map[string]string {
+	return map[string]string{"error": strings.TrimSpace(msg)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
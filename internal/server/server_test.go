@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"stellaris-mod-sorter-go/internal/mods"
+)
+
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	subDir := filepath.Join(dir, "sub")
+	os.Mkdir(subDir, 0755)
+	os.WriteFile(filepath.Join(subDir, "descriptor.mod"), []byte("tags={\n\t\"Fixes\"\n}\ndependencies={\n\t\"Base\"\n}\n"), 0644)
+	registry := `{
+		"h1": {"displayName": "Base", "gameRegistryId": "mod/base.mod"},
+		"h2": {"displayName": "Sub", "gameRegistryId": "mod/sub.mod", "dirPath": "` + filepath.ToSlash(subDir) + `"}
+	}`
+	os.WriteFile(filepath.Join(dir, mods.ModsRegistryFile), []byte(registry), 0644)
+	os.WriteFile(filepath.Join(dir, mods.DlcLoadFile), []byte(`{"enabled_mods": ["mod/base.mod", "mod/sub.mod"]}`), 0644)
+	os.WriteFile(filepath.Join(dir, mods.GameDataFile), []byte(`{"modsOrder": ["h2", "h1"]}`), 0644)
+	return New(dir, mods.StellarisRules), dir
+}
+
+// testHost is the Host of the requests do sends, the default serve address.
+const testHost = "127.0.0.1:8642"
+
+func do(t *testing.T, s *Server, method, path, body string, out interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = testHost
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return serve(t, s, req, out)
+}
+
+func serve(t *testing.T, s *Server, req *http.Request, out interface{}) int {
+	t.Helper()
+	method, path := req.Method, req.URL.Path
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if out != nil {
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid JSON response: %v", method, path, err)
+		}
+	}
+	return rec.Code
+}
+
+func hashKeys(views []ModView) []string {
+	var keys []string
+	for _, v := range views {
+		keys = append(keys, v.HashKey)
+	}
+	return keys
+}
+
+func TestServer_Orders(t *testing.T) {
+	s, _ := newTestServer(t)
+	var current, proposed []ModView
+	if code := do(t, s, http.MethodGet, "/api/order/current", "", &current); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if !reflect.DeepEqual(hashKeys(current), []string{"h2", "h1"}) {
+		t.Errorf("unexpected current order %v", hashKeys(current))
+	}
+	do(t, s, http.MethodGet, "/api/order/proposed", "", &proposed)
+	if !reflect.DeepEqual(hashKeys(proposed), []string{"h1", "h2"}) {
+		t.Errorf("unexpected proposed order %v", hashKeys(proposed))
+	}
+	var tags map[string][]string
+	do(t, s, http.MethodGet, "/api/tags", "", &tags)
+	if !reflect.DeepEqual(tags["Fixes"], []string{"Sub"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestServer_GameRules(t *testing.T) {
+	dir := t.TempDir()
+	aiDir := filepath.Join(dir, "ai")
+	os.Mkdir(aiDir, 0755)
+	os.WriteFile(filepath.Join(aiDir, "descriptor.mod"), []byte("tags={\n\t\"AI\"\n}\n"), 0644)
+	registry := `{
+		"h1": {"displayName": "Alpha", "gameRegistryId": "mod/alpha.mod"},
+		"h2": {"displayName": "Zulu AI", "gameRegistryId": "mod/zulu.mod", "dirPath": "` + filepath.ToSlash(aiDir) + `"}
+	}`
+	os.WriteFile(filepath.Join(dir, mods.ModsRegistryFile), []byte(registry), 0644)
+	os.WriteFile(filepath.Join(dir, mods.DlcLoadFile), []byte(`{"enabled_mods": ["mod/alpha.mod", "mod/zulu.mod"]}`), 0644)
+	os.WriteFile(filepath.Join(dir, mods.GameDataFile), []byte(`{"modsOrder": ["h1", "h2"]}`), 0644)
+
+	// AI is a late tag in Stellaris only, Hearts of Iron IV keeps the reverse alphabetical order
+	cases := map[string][]string{"stellaris": {"h1", "h2"}, "hoi4": {"h2", "h1"}}
+	for game, want := range cases {
+		var proposed []ModView
+		do(t, New(dir, mods.RulesForGame(game)), http.MethodGet, "/api/order/proposed", "", &proposed)
+		if !reflect.DeepEqual(hashKeys(proposed), want) {
+			t.Errorf("%s: expected proposed order %v, got %v", game, want, hashKeys(proposed))
+		}
+	}
+}
+
+func TestServer_SortAndRestore(t *testing.T) {
+	s, dir := newTestServer(t)
+	if code := do(t, s, http.MethodGet, "/api/sort", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected GET /api/sort to be rejected, got %d", code)
+	}
+	var dry []ModView
+	do(t, s, http.MethodGet, "/api/dry-run", "", &dry)
+	if p, _ := mods.LoadPlayset(dir); !reflect.DeepEqual(p.DisplayOrder(), []string{"h2", "h1"}) {
+		t.Errorf("dry run changed modsOrder to %v", p.DisplayOrder())
+	}
+	do(t, s, http.MethodPost, "/api/sort", "", nil)
+	if p, _ := mods.LoadPlayset(dir); !reflect.DeepEqual(p.DisplayOrder(), []string{"h1", "h2"}) {
+		t.Errorf("sort wrote modsOrder %v", p.DisplayOrder())
+	}
+	if code := do(t, s, http.MethodPost, "/api/backups/restore", "", nil); code != http.StatusOK {
+		t.Errorf("restore failed with %d", code)
+	}
+	if p, _ := mods.LoadPlayset(dir); !reflect.DeepEqual(p.DisplayOrder(), []string{"h2", "h1"}) {
+		t.Errorf("restore left modsOrder %v", p.DisplayOrder())
+	}
+}
+
+func TestServer_RestoreNeedsBothBackups(t *testing.T) {
+	s, dir := newTestServer(t)
+	dlcLoad := filepath.Join(dir, mods.DlcLoadFile)
+	os.WriteFile(dlcLoad+mods.BakExt, []byte(`{"enabled_mods": []}`), 0644)
+	if code := do(t, s, http.MethodPost, "/api/backups/restore", "", nil); code != http.StatusConflict {
+		t.Errorf("expected a missing game_data.json backup to be a conflict, got %d", code)
+	}
+	if p, _ := mods.LoadPlayset(dir); len(p.EnabledIds()) != 2 {
+		t.Errorf("restore changed dlc_load.json without a game_data.json backup: %v", p.EnabledIds())
+	}
+}
+
+func TestServer_PinsAndEnabled(t *testing.T) {
+	s, dir := newTestServer(t)
+	var pins map[string]int
+	do(t, s, http.MethodPost, "/api/pins", `{"hashKey": "h2", "index": 0}`, &pins)
+	if pins["h2"] != 0 {
+		t.Errorf("expected pin, got %v", pins)
+	}
+	if code := do(t, s, http.MethodPost, "/api/pins", `{"hashKey": "nope", "index": 0}`, nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown mod, got %d", code)
+	}
+	do(t, s, http.MethodPost, "/api/enabled", `{"hashKey": "h1", "enabled": false}`, nil)
+	p, _ := mods.LoadPlayset(dir)
+	if !reflect.DeepEqual(p.EnabledIds(), []string{"mod/sub.mod"}) {
+		t.Errorf("unexpected enabled_mods %v", p.EnabledIds())
+	}
+}
+
+func TestServer_RejectsForeignRequests(t *testing.T) {
+	s, dir := newTestServer(t)
+	post := func(host, origin, contentType string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/sort", strings.NewReader("{}"))
+		req.Host = host
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+	cases := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"foreign origin", post(testHost, "https://evil.example", "application/json"), http.StatusForbidden},
+		{"foreign host", post("evil.example:8642", "", "application/json"), http.StatusForbidden},
+		{"text body", post(testHost, "", "text/plain"), http.StatusUnsupportedMediaType},
+		{"rebound read", httptest.NewRequest(http.MethodGet, "http://evil.example:8642/api/mods", nil), http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if code := serve(t, s, tc.req, nil); code != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.want, code)
+		}
+	}
+	if p, _ := mods.LoadPlayset(dir); !reflect.DeepEqual(p.DisplayOrder(), []string{"h2", "h1"}) {
+		t.Errorf("a rejected request changed modsOrder to %v", p.DisplayOrder())
+	}
+	if code := serve(t, s, post("localhost:8642", "http://localhost:8642", "application/json; charset=utf-8"), nil); code != http.StatusOK {
+		t.Errorf("expected a same-origin request to pass, got %d", code)
+	}
+}
+
+func TestServer_CancelledSort(t *testing.T) {
+	s, dir := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/api/sort", strings.NewReader("{}")).WithContext(ctx)
+	req.Host = testHost
+	req.Header.Set("Content-Type", "application/json")
+	if code := serve(t, s, req, nil); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 for a request cancelled before the sort, got %d", code)
+	}
+	if p, _ := mods.LoadPlayset(dir); !reflect.DeepEqual(p.DisplayOrder(), []string{"h2", "h1"}) {
+		t.Errorf("a cancelled sort changed modsOrder to %v", p.DisplayOrder())
+	}
+}
+
+func TestServer_Index(t *testing.T) {
+	s, _ := newTestServer(t)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Stellaris Mod Sorter") {
+		t.Errorf("expected embedded index page, got %d", rec.Code)
+	}
+}
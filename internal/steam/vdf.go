@@ -0,0 +1,216 @@
+package steam
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Node is a Valve KeyValues (VDF) entry holding either a string value or child entries.
+type Node struct {
+	Key      string
+	Value    string
+	Children []*Node
+}
+
+// Get follows the given keys through nested children, matching keys case-insensitively.
+func (n *Node) Get(keys ...string) *Node {
+	cur := n
+	for _, key := range keys {
+		var next *Node
+		if cur != nil {
+			for _, c := range cur.Children {
+				if strings.EqualFold(c.Key, key) {
+					next = c
+					break
+				}
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
+
+// String returns the value at the given keys, or "" when it does not exist.
+func (n *Node) String(keys ...string) string {
+	if c := n.Get(keys...); c != nil {
+		return c.Value
+	}
+	return ""
+}
+
+// LoadVDF parses the VDF file at path.
+func LoadVDF(path string) (*Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseVDF(f)
+}
+
+// ParseVDF parses text KeyValues into a root node whose children are the top-level entries.
+func ParseVDF(r io.Reader) (*Node, error) {
+	p := &vdfParser{r: bufio.NewReader(r), line: 1}
+	root := &Node{}
+	if err := p.parseBlock(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+type vdfParser struct {
+	r    *bufio.Reader
+	line int
+}
+
+// parseBlock reads key/value pairs into parent until a closing brace (nested) or EOF (top level).
+func (p *vdfParser) parseBlock(parent *Node, nested bool) error {
+	for {
+		tok, quoted, err := p.token()
+		if err == io.EOF {
+			if nested {
+				return fmt.Errorf("vdf: line %d: unexpected end of file, missing }", p.line)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if tok == "}" && !quoted {
+			if !nested {
+				return fmt.Errorf("vdf: line %d: unexpected }", p.line)
+			}
+			return nil
+		}
+		if tok == "{" && !quoted {
+			return fmt.Errorf("vdf: line %d: unexpected {", p.line)
+		}
+		node := &Node{Key: tok}
+		val, quoted, err := p.token()
+		if err != nil {
+			return fmt.Errorf("vdf: line %d: missing value for %q", p.line, tok)
+		}
+		if val == "{" && !quoted {
+			if err := p.parseBlock(node, true); err != nil {
+				return err
+			}
+		} else if val == "}" && !quoted {
+			return fmt.Errorf("vdf: line %d: missing value for %q", p.line, tok)
+		} else {
+			node.Value = val
+		}
+		parent.Children = append(parent.Children, node)
+	}
+}
+
+// token returns the next quoted string, bare word or brace, skipping whitespace,
+// comments and conditional tags such as [$WIN32].
+func (p *vdfParser) token() (string, bool, error) {
+	for {
+		c, err := p.read()
+		if err != nil {
+			return "", false, err
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '/':
+			next, err := p.r.ReadByte()
+			if err == nil && next == '/' {
+				if err := p.skipLine(); err != nil {
+					return "", false, err
+				}
+				continue
+			}
+			if err == nil {
+				p.r.UnreadByte()
+			}
+			return p.bare(c)
+		case c == '[':
+			for c != ']' {
+				if c, err = p.read(); err != nil {
+					return "", false, err
+				}
+			}
+			continue
+		case c == '{' || c == '}':
+			return string(c), false, nil
+		case c == '"':
+			s, err := p.quoted()
+			return s, true, err
+		default:
+			return p.bare(c)
+		}
+	}
+}
+
+func (p *vdfParser) read() (byte, error) {
+	c, err := p.r.ReadByte()
+	if c == '\n' {
+		p.line++
+	}
+	return c, err
+}
+
+func (p *vdfParser) skipLine() error {
+	for {
+		c, err := p.read()
+		if err != nil || c == '\n' {
+			return err
+		}
+	}
+}
+
+func (p *vdfParser) quoted() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := p.read()
+		if err != nil {
+			return "", fmt.Errorf("vdf: line %d: unterminated string", p.line)
+		}
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			e, err := p.read()
+			if err != nil {
+				return "", fmt.Errorf("vdf: line %d: unterminated string", p.line)
+			}
+			switch e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+func (p *vdfParser) bare(first byte) (string, bool, error) {
+	sb := strings.Builder{}
+	sb.WriteByte(first)
+	for {
+		c, err := p.r.ReadByte()
+		if err == io.EOF {
+			return sb.String(), false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '{' || c == '}' || c == '"' {
+			p.r.UnreadByte()
+			return sb.String(), false, nil
+		}
+		sb.WriteByte(c)
+	}
+}
@@ -0,0 +1,47 @@
+package steam
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseVDF(t *testing.T) {
+	src := `// comment
+"libraryfolders"
+{
+	"0"
+	{
+		"path"		"C:\\Program Files (x86)\\Steam"
+		"apps"
+		{
+			"281990"		"123"
+		}
+	}
+	"contentstatsid"	"-1" [$WIN32]
+	bare	value
+}`
+	root, err := ParseVDF(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseVDF failed: %v", err)
+	}
+	if got := root.String("libraryfolders", "0", "path"); got != `C:\Program Files (x86)\Steam` {
+		t.Errorf("unexpected path %q", got)
+	}
+	if got := root.String("LibraryFolders", "0", "APPS", "281990"); got != "123" {
+		t.Errorf("expected case-insensitive lookup, got %q", got)
+	}
+	if got := root.String("libraryfolders", "bare"); got != "value" {
+		t.Errorf("unexpected bare value %q", got)
+	}
+	if root.Get("libraryfolders", "missing") != nil {
+		t.Error("expected nil for missing key")
+	}
+}
+
+func TestParseVDF_Errors(t *testing.T) {
+	for _, src := range []string{`"a" {`, `"a" "b" }`, `"a" "unterminated`, `"a"`} {
+		if _, err := ParseVDF(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StellarisAppId is the Steam app ID of Stellaris.
+const StellarisAppId = "281990"
+
+// FlatpakSteamDir is the data directory of Flatpak Steam relative to the home directory.
+const FlatpakSteamDir = ".var/app/com.valvesoftware.Steam"
+
+// WorkshopItem holds what Steam records about one installed workshop item.
+type WorkshopItem struct {
+	PublishedFileId string
+	Size            int64
+	TimeUpdated     int64
+	TimeTouched     int64
+	Subscribed      bool
+	Library         string
+}
+
+// DefaultSteamRoots returns the usual Steam installation directories for the given home directory.
+func DefaultSteamRoots(home string) []string {
+	return SteamRoots(home, os.Getenv)
+}
+
+// SteamRoots returns the usual Steam installation directories for home, including Flatpak
+// Steam and XDG_DATA_HOME, reading the environment through getenv.
+func SteamRoots(home string, getenv func(string) string) []string {
+	dataHome := getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	roots := []string{
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(dataHome, "Steam"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, FlatpakSteamDir, ".local", "share", "Steam"),
+		filepath.Join(home, FlatpakSteamDir, ".steam", "steam"),
+	}
+	if pf := getenv("ProgramFiles(x86)"); pf != "" {
+		roots = append(roots, filepath.Join(pf, "Steam"))
+	}
+	return uniquePaths(roots)
+}
+
+// LibraryFolders returns steamRoot plus every library listed in its steamapps/libraryfolders.vdf.
+func LibraryFolders(steamRoot string) ([]string, error) {
+	root, err := LoadVDF(LibraryFoldersPath(steamRoot))
+	if err != nil {
+		return []string{steamRoot}, err
+	}
+	return ParseLibraryFolders(steamRoot, root), nil
+}
+
+// LibraryFoldersPath returns the path of the libraryfolders.vdf of steamRoot.
+func LibraryFoldersPath(steamRoot string) string {
+	return filepath.Join(steamRoot, "steamapps", "libraryfolders.vdf")
+}
+
+// ParseLibraryFolders returns steamRoot plus every library listed in a parsed libraryfolders.vdf.
+func ParseLibraryFolders(steamRoot string, root *Node) []string {
+	libraries := []string{steamRoot}
+	folders := root.Get("libraryfolders")
+	if folders == nil {
+		return libraries
+	}
+	for _, entry := range folders.Children {
+		if _, err := strconv.Atoi(entry.Key); err != nil {
+			continue
+		}
+		// Newer files nest the path in a block, older ones store it as the value
+		path := entry.Value
+		if path == "" {
+			path = entry.String("path")
+		}
+		if path != "" {
+			libraries = append(libraries, path)
+		}
+	}
+	return uniquePaths(libraries)
+}
+
+// LibraryFromContentPath returns the library containing a workshop content directory
+// such as <library>/steamapps/workshop/content/281990/<id>.
+func LibraryFromContentPath(dirPath string) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(dirPath), "/")
+	for i := len(parts) - 1; i >= 2; i-- {
+		if strings.EqualFold(parts[i], "content") && strings.EqualFold(parts[i-1], "workshop") && strings.EqualFold(parts[i-2], "steamapps") {
+			return filepath.FromSlash(strings.Join(parts[:i-2], "/")), true
+		}
+	}
+	return "", false
+}
+
+// LoadWorkshopManifest reads steamapps/workshop/appworkshop_<appId>.acf of one library.
+func LoadWorkshopManifest(library, appId string) (map[string]*WorkshopItem, error) {
+	root, err := LoadVDF(filepath.Join(library, "steamapps", "workshop", "appworkshop_"+appId+".acf"))
+	if err != nil {
+		return nil, err
+	}
+	items := map[string]*WorkshopItem{}
+	item := func(id string) *WorkshopItem {
+		if items[id] == nil {
+			items[id] = &WorkshopItem{PublishedFileId: id, Library: library}
+		}
+		return items[id]
+	}
+	if installed := root.Get("AppWorkshop", "WorkshopItemsInstalled"); installed != nil {
+		for _, n := range installed.Children {
+			it := item(n.Key)
+			it.Size = parseInt(n.String("size"))
+			it.TimeUpdated = parseInt(n.String("timeupdated"))
+		}
+	}
+	if details := root.Get("AppWorkshop", "WorkshopItemDetails"); details != nil {
+		for _, n := range details.Children {
+			it := item(n.Key)
+			if t := parseInt(n.String("timeupdated")); t > it.TimeUpdated {
+				it.TimeUpdated = t
+			}
+			it.TimeTouched = parseInt(n.String("timetouched"))
+			it.Subscribed = n.String("subscribedby") != ""
+		}
+	}
+	return items, nil
+}
+
+// FindWorkshopItems reads the workshop manifests of every library reachable from steamRoots
+// and extraLibraries. It returns the items by published file ID and the libraries that had a manifest.
+func FindWorkshopItems(steamRoots, extraLibraries []string, appId string) (map[string]*WorkshopItem, []string) {
+	var libraries []string
+	for _, root := range steamRoots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		found, _ := LibraryFolders(root)
+		libraries = append(libraries, found...)
+	}
+	libraries = uniquePaths(append(libraries, extraLibraries...))
+	items := map[string]*WorkshopItem{}
+	var used []string
+	for _, library := range libraries {
+		found, err := LoadWorkshopManifest(library, appId)
+		if err != nil {
+			continue
+		}
+		used = append(used, library)
+		for id, it := range found {
+			if prev, ok := items[id]; !ok || it.TimeUpdated > prev.TimeUpdated {
+				items[id] = it
+			}
+		}
+	}
+	return items, used
+}
+
+// FindAppInstall returns the install folder of appId from the steamapps/appmanifest_<appId>.acf
+// of the first library reachable from steamRoots that has one.
+func FindAppInstall(steamRoots []string, appId string) (string, bool) {
+	for _, root := range steamRoots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		libraries, _ := LibraryFolders(root)
+		for _, library := range libraries {
+			manifest, err := LoadVDF(filepath.Join(library, "steamapps", "appmanifest_"+appId+".acf"))
+			if err != nil {
+				continue
+			}
+			if dir := manifest.String("AppState", "installdir"); dir != "" {
+				return filepath.Join(library, "steamapps", "common", dir), true
+			}
+		}
+	}
+	return "", false
+}
+
+// SortedIds returns the IDs of items in ascending order.
+func SortedIds(items map[string]*WorkshopItem) []string {
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func parseInt(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// uniquePaths removes duplicate paths, comparing them after filepath.Clean.
+func uniquePaths(paths []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, p := range paths {
+		c := filepath.Clean(p)
+		if !seen[c] {
+			seen[c] = true
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testManifest = `"AppWorkshop"
+{
+	"appid"		"281990"
+	"WorkshopItemsInstalled"
+	{
+		"111"
+		{
+			"size"		"2048"
+			"timeupdated"		"1700000000"
+		}
+	}
+	"WorkshopItemDetails"
+	{
+		"111"
+		{
+			"timeupdated"		"1700000100"
+			"timetouched"		"1700000200"
+			"subscribedby"		"42"
+		}
+		"222"
+		{
+			"timetouched"		"5"
+		}
+	}
+}`
+
+func writeLibrary(t *testing.T, dir, manifest string) {
+	t.Helper()
+	os.MkdirAll(filepath.Join(dir, "steamapps", "workshop"), 0755)
+	os.WriteFile(filepath.Join(dir, "steamapps", "workshop", "appworkshop_"+StellarisAppId+".acf"), []byte(manifest), 0644)
+}
+
+func TestLoadWorkshopManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeLibrary(t, dir, testManifest)
+	items, err := LoadWorkshopManifest(dir, StellarisAppId)
+	if err != nil {
+		t.Fatalf("LoadWorkshopManifest failed: %v", err)
+	}
+	want := &WorkshopItem{PublishedFileId: "111", Size: 2048, TimeUpdated: 1700000100, TimeTouched: 1700000200, Subscribed: true, Library: dir}
+	if !reflect.DeepEqual(items["111"], want) {
+		t.Errorf("got %+v, want %+v", items["111"], want)
+	}
+	if !reflect.DeepEqual(SortedIds(items), []string{"111", "222"}) {
+		t.Errorf("unexpected ids %v", SortedIds(items))
+	}
+}
+
+func TestFindWorkshopItems_SecondaryLibrary(t *testing.T) {
+	root := t.TempDir()
+	second := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "steamapps"), 0755)
+	folders := `"libraryfolders" { "0" { "path" "` + filepath.ToSlash(root) + `" } "1" { "path" "` + filepath.ToSlash(second) + `" } }`
+	os.WriteFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"), []byte(folders), 0644)
+	writeLibrary(t, second, testManifest)
+	items, used := FindWorkshopItems([]string{root}, nil, StellarisAppId)
+	if len(used) != 1 || filepath.Clean(used[0]) != filepath.Clean(second) {
+		t.Errorf("expected only the secondary library to have a manifest, got %v", used)
+	}
+	if items["111"] == nil || items["111"].Size != 2048 {
+		t.Errorf("expected item 111 from secondary library, got %v", items)
+	}
+}
+
+func TestLibraryFromContentPath(t *testing.T) {
+	lib, ok := LibraryFromContentPath("/home/u/.local/share/Steam/steamapps/workshop/content/281990/123")
+	if !ok || filepath.ToSlash(lib) != "/home/u/.local/share/Steam" {
+		t.Errorf("unexpected library %q, %v", lib, ok)
+	}
+	if _, ok := LibraryFromContentPath("/home/u/mods/local"); ok {
+		t.Error("expected no library for local mod")
+	}
+}
+
+func TestSteamRoots_FlatpakAndXDG(t *testing.T) {
+	env := map[string]string{"XDG_DATA_HOME": "/data"}
+	roots := SteamRoots("/home/u", func(k string) string { return env[k] })
+	want := []string{
+		"/home/u/.steam/steam",
+		"/data/Steam",
+		"/home/u/.local/share/Steam",
+		"/home/u/.var/app/com.valvesoftware.Steam/.local/share/Steam",
+		"/home/u/.var/app/com.valvesoftware.Steam/.steam/steam",
+	}
+	if !reflect.DeepEqual(roots, want) {
+		t.Errorf("expected %v, got %v", want, roots)
+	}
+}
+
+func TestFindAppInstall_SecondaryLibrary(t *testing.T) {
+	root := t.TempDir()
+	second := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "steamapps"), 0755)
+	folders := `"libraryfolders" { "0" { "path" "` + filepath.ToSlash(root) + `" } "1" { "path" "` + filepath.ToSlash(second) + `" } }`
+	os.WriteFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"), []byte(folders), 0644)
+	os.MkdirAll(filepath.Join(second, "steamapps"), 0755)
+	os.WriteFile(filepath.Join(second, "steamapps", "appmanifest_"+StellarisAppId+".acf"), []byte(`"AppState" { "appid" "281990" "installdir" "Stellaris" }`), 0644)
+	dir, ok := FindAppInstall([]string{root}, StellarisAppId)
+	if !ok || filepath.Clean(dir) != filepath.Join(second, "steamapps", "common", "Stellaris") {
+		t.Errorf("unexpected install folder %q, %v", dir, ok)
+	}
+	if _, ok := FindAppInstall([]string{root}, "1"); ok {
+		t.Error("expected no install folder for an app without a manifest")
+	}
+}
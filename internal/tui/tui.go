@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"stellaris-mod-sorter-go/internal/mods"
+)
+
+// ANSI color codes
+const (
+	gray   = "\033[90m"
+	green  = "\033[32m"
+	orange = "\033[38;5;208m"
+	red    = "\033[31m"
+	bold   = "\033[1m"
+	reset  = "\033[0m"
+)
+
+const nameWidth = 36
+
+const helpText = `commands:
+  m <from> <to>   move the mod at <from> to <to>
+  u <n> / d <n>   move the mod at <n> up / down one row
+  p <n>           pin or unpin the mod at <n> to its current row
+  e <n>           enable or disable the mod at <n>
+  i <n>           show tags, dependencies and warnings of the mod at <n>
+  r               reset to the proposed order
+  c               reset to the current order
+  w               write the order, enabled mods and pins
+  q               quit without writing
+  ?               show this help`
+
+// Session holds the order being edited next to the current and proposed orders.
+type Session struct {
+	playset  *mods.Playset
+	current  []*mods.Mod
+	proposed []*mods.Mod
+	order    []*mods.Mod
+	enabled  map[string]bool
+	pins     map[string]int
+	dirty    bool
+}
+
+// NewSession sorts the playset and starts editing from the proposed order. It fails only
+// when ctx is cancelled during the sort.
+func NewSession(ctx context.Context, p *mods.Playset) (*Session, error) {
+	enabled := map[string]bool{}
+	idList := p.EnabledIds()
+	for _, id := range idList {
+		enabled[id] = true
+	}
+	proposed, err := p.ProposedOrder(ctx, idList)
+	if err != nil {
+		return nil, err
+	}
+	pins, _ := mods.LoadPins(p.SettingsPath)
+	s := &Session{
+		playset:  p,
+		current:  p.CurrentOrder(proposed),
+		proposed: proposed,
+		enabled:  enabled,
+		pins:     pins,
+	}
+	s.order = append([]*mods.Mod{}, proposed...)
+	return s, nil
+}
+
+// Run renders the session and executes commands read from in until quit or EOF.
+func (s *Session) Run(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	s.Render(out)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		quit, err := s.Exec(scanner.Text(), out)
+		if err != nil {
+			fmt.Fprintf(out, "%s%s%s\n", red, err.Error(), reset)
+			continue
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// Exec runs a single command line and reports whether the session should end.
+func (s *Session) Exec(line string, out io.Writer) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		s.Render(out)
+		return false, nil
+	}
+	args, err := s.parseRows(fields[1:])
+	if err != nil {
+		return false, err
+	}
+	switch fields[0] {
+	case "m":
+		if len(args) != 2 {
+			return false, fmt.Errorf("usage: m <from> <to>")
+		}
+		s.move(args[0], args[1])
+	case "u", "d":
+		if len(args) != 1 {
+			return false, fmt.Errorf("usage: %s <n>", fields[0])
+		}
+		to := args[0] - 1
+		if fields[0] == "d" {
+			to = args[0] + 1
+		}
+		if to < 0 || to >= len(s.order) {
+			return false, nil
+		}
+		s.move(args[0], to)
+	case "p":
+		if len(args) != 1 {
+			return false, fmt.Errorf("usage: p <n>")
+		}
+		mod := s.order[args[0]]
+		if _, ok := s.pins[mod.HashKey]; ok {
+			delete(s.pins, mod.HashKey)
+		} else {
+			s.pins[mod.HashKey] = args[0]
+		}
+		s.dirty = true
+	case "e":
+		if len(args) != 1 {
+			return false, fmt.Errorf("usage: e <n>")
+		}
+		mod := s.order[args[0]]
+		s.enabled[mod.ModId] = !s.enabled[mod.ModId]
+		s.dirty = true
+	case "i":
+		if len(args) != 1 {
+			return false, fmt.Errorf("usage: i <n>")
+		}
+		s.renderInfo(out, s.order[args[0]])
+		return false, nil
+	case "r":
+		s.order = append([]*mods.Mod{}, s.proposed...)
+		s.dirty = true
+	case "c":
+		s.order = append([]*mods.Mod{}, s.current...)
+		s.dirty = true
+	case "w":
+		s.playset.Command = "tui"
+		s.playset.Write(s.order, s.EnabledIds(), mods.BakExt)
+		if err := mods.SavePins(s.playset.SettingsPath, s.pins); err != nil {
+			return false, fmt.Errorf("could not save pins: %w", err)
+		}
+		s.dirty = false
+		fmt.Fprintf(out, "%swrote %s and %s%s\n", green, s.playset.DlcLoadPath, s.playset.GameDataPath, reset)
+		return false, nil
+	case "q":
+		if s.dirty {
+			fmt.Fprintf(out, "%sdiscarding unsaved changes%s\n", orange, reset)
+		}
+		return true, nil
+	case "?", "h", "help":
+		fmt.Fprintln(out, helpText)
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown command %q, type ? for help", fields[0])
+	}
+	s.Render(out)
+	return false, nil
+}
+
+// Order returns the order being edited.
+func (s *Session) Order() []*mods.Mod {
+	return s.order
+}
+
+// EnabledIds returns the enabled mod IDs in the edited order.
+func (s *Session) EnabledIds() []string {
+	var ids []string
+	for _, mod := range s.order {
+		if s.enabled[mod.ModId] {
+			ids = append(ids, mod.ModId)
+		}
+	}
+	return ids
+}
+
+// Violations returns the dependency problems of the edited order.
+func (s *Session) Violations() map[*mods.Mod][]mods.Violation {
+	result := map[*mods.Mod][]mods.Violation{}
+	for _, v := range mods.CheckDependencyOrder(s.order, s.playset.Registry, s.EnabledIds()) {
+		result[v.Mod] = append(result[v.Mod], v)
+	}
+	return result
+}
+
+// Render prints the current and edited orders side by side.
+func (s *Session) Render(out io.Writer) {
+	violations := s.Violations()
+	fmt.Fprintf(out, "%s%4s  %-*s  %-5s %-*s  %s%s\n", bold, "#", nameWidth, "current", "flags", nameWidth, "order", "tags", reset)
+	for i, mod := range s.order {
+		cur := ""
+		if i < len(s.current) {
+			cur = s.current[i].Name
+		}
+		color := ""
+		if !s.enabled[mod.ModId] {
+			color = gray
+		}
+		for _, v := range violations[mod] {
+			if v.Kind == mods.ViolationDependency {
+				color = red
+			}
+		}
+		fmt.Fprintf(out, "%4d  %-*s  %-5s %s%-*s%s  %s\n",
+			i, nameWidth, truncate(cur), s.flags(mod),
+			color, nameWidth, truncate(mod.Name), reset,
+			strings.Join(mod.Tags, ", "))
+		for _, v := range violations[mod] {
+			if v.Kind == mods.ViolationDependency {
+				fmt.Fprintf(out, "%6s%s! %s%s\n", "", red, v.Cause, reset)
+			}
+		}
+	}
+	if s.dirty {
+		fmt.Fprintf(out, "%sunsaved changes, w to write%s\n", orange, reset)
+	}
+}
+
+func (s *Session) renderInfo(out io.Writer, mod *mods.Mod) {
+	fmt.Fprintf(out, "%s%s%s (%s)\n", bold, mod.Name, reset, mod.ModId)
+	fmt.Fprintf(out, "  tags: %s\n", strings.Join(mod.Tags, ", "))
+	fmt.Fprintf(out, "  dependencies: %s\n", strings.Join(mod.Dependencies, ", "))
+	for _, v := range s.Violations()[mod] {
+		fmt.Fprintf(out, "  %s%s: %s%s\n", orange, v.Kind, v.Cause, reset)
+	}
+	deps := mods.NewDependencyIndex(s.playset.Registry)
+	idList := s.EnabledIds()
+	for _, dep := range mod.Dependencies {
+		match, found := deps.Resolve(dep)
+		if !found || !s.enabled[mod.ModId] {
+			continue
+		}
+		h := match.Prefer(s.playset.Registry, idList)
+		for _, other := range s.order {
+			if other.HashKey == h && !s.enabled[other.ModId] {
+				fmt.Fprintf(out, "  %sdependency %s is disabled%s\n", orange, dep, reset)
+			}
+		}
+	}
+}
+
+func (s *Session) flags(mod *mods.Mod) string {
+	flags := ""
+	if s.enabled[mod.ModId] {
+		flags += "E"
+	} else {
+		flags += "-"
+	}
+	if _, ok := s.pins[mod.HashKey]; ok {
+		flags += "P"
+	}
+	return flags
+}
+
+func (s *Session) move(from, to int) {
+	mod := s.order[from]
+	s.order = append(s.order[:from], s.order[from+1:]...)
+	s.order = append(s.order[:to], append([]*mods.Mod{mod}, s.order[to:]...)...)
+	if _, ok := s.pins[mod.HashKey]; ok {
+		s.pins[mod.HashKey] = to
+	}
+	s.dirty = true
+}
+
+func (s *Session) parseRows(fields []string) ([]int, error) {
+	rows := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 || n >= len(s.order) {
+			return nil, fmt.Errorf("invalid row %q", f)
+		}
+		rows = append(rows, n)
+	}
+	return rows, nil
+}
+
+func truncate(name string) string {
+	r := []rune(name)
+	if len(r) > nameWidth {
+		return string(r[:nameWidth-1]) + "…"
+	}
+	return name
+}
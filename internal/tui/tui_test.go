@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"stellaris-mod-sorter-go/internal/mods"
+)
+
+func newTestSession(t *testing.T) (*Session, string) {
+	t.Helper()
+	dir := t.TempDir()
+	subDir := filepath.Join(dir, "sub")
+	os.Mkdir(subDir, 0755)
+	os.WriteFile(filepath.Join(subDir, "descriptor.mod"), []byte("dependencies={\n\t\"Base\"\n}\n"), 0644)
+	registry := `{
+		"h1": {"displayName": "Base", "gameRegistryId": "mod/base.mod"},
+		"h2": {"displayName": "Sub", "gameRegistryId": "mod/sub.mod", "dirPath": "` + filepath.ToSlash(subDir) + `"}
+	}`
+	os.WriteFile(filepath.Join(dir, mods.ModsRegistryFile), []byte(registry), 0644)
+	os.WriteFile(filepath.Join(dir, mods.DlcLoadFile), []byte(`{"enabled_mods": ["mod/sub.mod", "mod/base.mod"]}`), 0644)
+	os.WriteFile(filepath.Join(dir, mods.GameDataFile), []byte(`{"modsOrder": ["h1", "h2"]}`), 0644)
+	p, err := mods.LoadPlayset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSession(context.Background(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, dir
+}
+
+func TestSession_MoveHighlightsViolation(t *testing.T) {
+	s, _ := newTestSession(t)
+	var out bytes.Buffer
+	if len(s.Violations()) != 0 {
+		t.Fatalf("expected proposed order without violations")
+	}
+	s.Exec("m 1 0", &out)
+	if len(s.Violations()) != 1 {
+		t.Errorf("expected one violation after moving Sub above Base")
+	}
+	if !strings.Contains(out.String(), "above its dependency Base") {
+		t.Errorf("expected violation in rendered output, got %q", out.String())
+	}
+}
+
+func TestSession_ToggleEnabledAndPin(t *testing.T) {
+	s, _ := newTestSession(t)
+	var out bytes.Buffer
+	s.Exec("e 0", &out)
+	if !reflect.DeepEqual(s.EnabledIds(), []string{"mod/sub.mod"}) {
+		t.Errorf("expected only Sub enabled, got %v", s.EnabledIds())
+	}
+	s.Exec("p 1", &out)
+	if s.pins["h2"] != 1 {
+		t.Errorf("expected Sub pinned at 1, got %v", s.pins)
+	}
+	s.Exec("p 1", &out)
+	if _, ok := s.pins["h2"]; ok {
+		t.Error("expected second p to unpin")
+	}
+}
+
+func TestSession_Write(t *testing.T) {
+	s, dir := newTestSession(t)
+	var out bytes.Buffer
+	in := strings.NewReader("p 0\nw\nq\n")
+	if err := s.Run(in, &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	p, _ := mods.LoadPlayset(dir)
+	if !reflect.DeepEqual(p.DisplayOrder(), []string{"h1", "h2"}) {
+		t.Errorf("unexpected modsOrder %v", p.DisplayOrder())
+	}
+	if !reflect.DeepEqual(p.EnabledIds(), []string{"mod/sub.mod", "mod/base.mod"}) {
+		t.Errorf("unexpected enabled_mods %v", p.EnabledIds())
+	}
+	pins, _ := mods.LoadPins(dir)
+	if pins["h1"] != 0 {
+		t.Errorf("expected pin to be saved, got %v", pins)
+	}
+}
+
+func TestSession_InvalidRow(t *testing.T) {
+	s, _ := newTestSession(t)
+	if _, err := s.Exec("m 0 7", &bytes.Buffer{}); err == nil {
+		t.Error("expected error for out of range row")
+	}
+}
+
+func TestSession_InfoPrefersEnabledDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	subDir := filepath.Join(dir, "sub")
+	os.Mkdir(subDir, 0755)
+	os.WriteFile(filepath.Join(subDir, "descriptor.mod"), []byte("dependencies={\n\t\"Base\"\n}\n"), 0644)
+	registry := `{
+		"h1": {"displayName": "Base", "gameRegistryId": "mod/base.mod"},
+		"h2": {"displayName": "Sub", "gameRegistryId": "mod/sub.mod", "dirPath": "` + filepath.ToSlash(subDir) + `"},
+		"h3": {"displayName": "Base", "gameRegistryId": "mod/base_local.mod"}
+	}`
+	os.WriteFile(filepath.Join(dir, mods.ModsRegistryFile), []byte(registry), 0644)
+	os.WriteFile(filepath.Join(dir, mods.DlcLoadFile), []byte(`{"enabled_mods": ["mod/sub.mod", "mod/base_local.mod"]}`), 0644)
+	os.WriteFile(filepath.Join(dir, mods.GameDataFile), []byte(`{"modsOrder": ["h3", "h1", "h2"]}`), 0644)
+	p, err := mods.LoadPlayset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSession(context.Background(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := -1
+	for i, mod := range s.order {
+		if mod.HashKey == "h2" {
+			row = i
+		}
+	}
+	var out bytes.Buffer
+	s.Exec(fmt.Sprintf("i %d", row), &out)
+	if strings.Contains(out.String(), "is disabled") {
+		t.Errorf("expected the enabled copy of Base to satisfy Sub, got:\n%s", out.String())
+	}
+}
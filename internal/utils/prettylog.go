@@ -2,6 +2,7 @@ package prettylog
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -19,6 +20,9 @@ const (
 	reset  = "\033[0m"
 )
 
+// Output receives every log line. Benchmarks and tests may replace it with io.Discard.
+var Output io.Writer = os.Stdout
+
 type LogType string
 
 const (
@@ -53,7 +57,7 @@ func PrintPretty(function, message string, logType LogType) {
 	}
 
 	// Print: date [function] [TYPE] message
-	fmt.Printf("%s%s%s [%s%s%s] %s%s%s%s\n",
+	fmt.Fprintf(Output, "%s%s%s [%s%s%s] %s%s%s%s\n",
 		dateColor, timestamp, reset,
 		funcColor, function, reset,
 		typeStr,